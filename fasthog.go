@@ -7,35 +7,58 @@
 //
 // Usage:
 //
-//	fasthog <directory> [--types=<extensions>] [--output=<file>]
+//	fasthog <directory> [--types=<extensions>] [--output=<file>] [--fail-on=<threshold>]
+//	fasthog <path>... [flags]
+//	fasthog --staged [directory]
+//	fasthog install-hook [directory] [--force]
 //
 // Arguments:
 //
 //	directory              Directory to scan for secrets
+//	path                   File, directory, or - for standard input
+//	--files-from=<file>    Also scan the paths listed in a file
+//	--staged               Scan only the changes staged for the next commit
 //	--types=<extensions>   Comma-separated file extensions to scan (e.g., py,js,yml)
+//	--filenames=<globs>    Comma-separated file names to scan (e.g., Dockerfile)
 //	--output=<file>        Write results to specified file
+//	--fail-on=<threshold>  Minimum finding count or severity that fails the run
+//
+// Exit codes:
+//
+//	0  no findings at or above the --fail-on threshold
+//	1  findings at or above the --fail-on threshold
+//	2  usage or configuration error
+//	3  error while scanning or writing results
 //
 // Example:
 //
 //	fasthog /path/to/repo --types=py,js --output=results.txt
 //
+// The install-hook subcommand writes a git pre-commit hook that runs
+// fasthog --staged.
+//
 // The tool uses concurrent processing to scan multiple files in parallel,
 // with the number of workers matching the available CPU cores.
+//
+// The scanning engine lives in the importable package
+// github.com/bordenet/secrets-in-source/pkg/fasthog; this command is a thin
+// CLI over it.
 package main
 
 import (
 	"bufio"
 	"bytes"
-	"embed"
+	"context"
 	"encoding/json"
-
+	"errors"
 	"fmt"
-	"io/fs"
+	"maps"
 	"os"
+	"path/filepath"
 	"regexp"
-	"runtime"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -43,11 +66,11 @@ import (
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/pflag"
-)
 
-//go:embed *.regex
-var regexFS embed.FS
+	"github.com/bordenet/secrets-in-source/pkg/fasthog"
+)
 
 // UI styles for terminal output formatting.
 var (
@@ -58,32 +81,83 @@ var (
 	styleTableRow    = lipgloss.NewStyle().Render
 )
 
-// defaultExcludeDirs contains directory names to skip during scanning.
-var defaultExcludeDirs = []string{
-	".git", ".github", "node_modules", "vendor", ".idea", ".vscode",
-}
-
-// defaultExtensions contains file extensions to scan when no specific types are provided.
-var defaultExtensions = []string{
-	".js", ".json", ".py", ".cs", ".go", ".java", ".sh", ".tf", ".yml", ".yaml",
-	".env", "env", ".ENV", "ENV", ".key", ".backup", ".tfstate", ".ts", ".txt",
-	".md", ".properties",
-}
-
 // OutputFormat represents the supported output formats for fasthog.
 type OutputFormat string
 
 const (
-	OutputFormatText OutputFormat = "text"
-	OutputFormatJSON OutputFormat = "json"
+	OutputFormatText  OutputFormat = "text"
+	OutputFormatJSON  OutputFormat = "json"
+	OutputFormatSARIF OutputFormat = "sarif"
 )
 
-// Match represents a single detected secret occurrence.
-type Match struct {
-	File        string `json:"file"`
-	Line        int    `json:"line"`
-	LineSnippet string `json:"line_snippet"`
-	MatchText   string `json:"match_text"`
+// Process exit codes returned by main.
+const (
+	exitClean     = 0
+	exitFindings  = 1
+	exitUsage     = 2
+	exitScanError = 3
+)
+
+// usageError marks failures caused by invalid input or configuration rather
+// than by the scan itself, so main can report them with exitUsage.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// exitCodeForError maps an error returned by runFasthog or runFasthogJSON to
+// the process exit code.
+func exitCodeForError(err error) int {
+	var uerr usageError
+	if errors.As(err, &uerr) {
+		return exitUsage
+	}
+	return exitScanError
+}
+
+// failThreshold decides whether the findings of a completed scan fail the run.
+// Exactly one of MinCount or MinSeverity is set.
+type failThreshold struct {
+	MinCount    int
+	MinSeverity fasthog.Severity
+}
+
+// defaultFailOn fails the run on any finding.
+const defaultFailOn = "1"
+
+// parseFailOn parses the --fail-on value, which is either a minimum number of
+// findings (e.g. "5") or a minimum severity (e.g. "high").
+func parseFailOn(value string) (failThreshold, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = defaultFailOn
+	}
+
+	if n, err := strconv.Atoi(value); err == nil {
+		if n < 1 {
+			return failThreshold{}, fmt.Errorf("invalid --fail-on count %d (must be at least 1)", n)
+		}
+		return failThreshold{MinCount: n}, nil
+	}
+
+	sev, err := fasthog.ParseSeverity(value)
+	if err != nil {
+		return failThreshold{}, fmt.Errorf("invalid --fail-on value %q (expected a count or one of: low, medium, high, critical)", value)
+	}
+	return failThreshold{MinSeverity: sev}, nil
+}
+
+// exceeded reports whether matches meet or exceed the threshold.
+func (t failThreshold) exceeded(matches []fasthog.Match) bool {
+	if t.MinSeverity != "" {
+		minRank := fasthog.SeverityRank(t.MinSeverity)
+		return slices.ContainsFunc(matches, func(m fasthog.Match) bool {
+			return fasthog.SeverityRank(m.Severity) >= minRank
+		})
+	}
+	return len(matches) >= max(t.MinCount, 1)
 }
 
 // FileMatchCount represents the number of matches found in a single file.
@@ -97,17 +171,28 @@ type ScanSummary struct {
 	TotalMatches        int `json:"total_matches"`
 	TotalFilesWithMatch int `json:"total_files_with_matches"`
 	TotalFilesScanned   int `json:"total_files_scanned"`
+	TotalErrors         int `json:"total_errors"`
+	TotalWarnings       int `json:"total_warnings"`
+	TotalSuppressed     int `json:"total_suppressed"`
+	TotalIgnored        int `json:"total_ignored"`
+	TotalSkipped        int `json:"total_skipped"`
+	TotalPlaceholders   int `json:"total_placeholders"`
 }
 
 // JSONResult is the top-level structure emitted when using JSON output format.
 type JSONResult struct {
-	Directory  string           `json:"directory"`
-	Extensions []string         `json:"extensions"`
-	StartTime  time.Time        `json:"start_time"`
-	DurationMs int64            `json:"duration_ms"`
-	Matches    []Match          `json:"matches"`
-	Summary    ScanSummary      `json:"summary"`
-	TopFiles   []FileMatchCount `json:"top_files"`
+	Directory  string                `json:"directory"`
+	Targets    []string              `json:"targets,omitempty"`
+	Extensions []string              `json:"extensions"`
+	StartTime  time.Time             `json:"start_time"`
+	DurationMs int64                 `json:"duration_ms"`
+	Matches    []fasthog.Match       `json:"matches"`
+	Errors     []fasthog.ScanError   `json:"errors"`
+	Warnings   []fasthog.ScanWarning `json:"warnings"`
+	// Placeholders is only included with --verbose.
+	Placeholders []fasthog.Placeholder `json:"placeholders,omitempty"`
+	Summary      ScanSummary           `json:"summary"`
+	TopFiles     []FileMatchCount      `json:"top_files"`
 }
 
 // parseOutputFormat converts a user-supplied string into an OutputFormat value.
@@ -117,169 +202,72 @@ func parseOutputFormat(format string) (OutputFormat, error) {
 		return OutputFormatText, nil
 	case string(OutputFormatJSON):
 		return OutputFormatJSON, nil
+	case string(OutputFormatSARIF):
+		return OutputFormatSARIF, nil
 	default:
-		return "", fmt.Errorf("invalid output format %q (supported: text, json)", format)
-	}
-}
-
-// scanOptions controls the behaviour of the core scanning engine.
-type scanOptions struct {
-	Directory   string
-	Extensions  []string
-	ExcludeDirs []string
-
-	ExcludePatterns *regexp.Regexp
-	FastPatterns    *regexp.Regexp
-	SlowPatterns    *regexp.Regexp
-
-	// OnCurrentFile, if non-nil, is invoked whenever a file is about to be scanned.
-	// index is zero-based, total is the total number of files to scan.
-	OnCurrentFile func(path string, index, total int)
-
-	// OnMatch, if non-nil, is invoked whenever a matching line is found.
-	OnMatch func(path string, lineNo int, line string, match string)
-}
-
-// scanResult captures the structured output from a scan.
-type scanResult struct {
-	Matches    []Match
-	MatchFiles map[string]int
-	Filenames  []string
-}
-
-// mergeExcludeDirs returns the union of defaultExcludeDirs and any additional
-// directories supplied in extra, preserving order and avoiding duplicates.
-func mergeExcludeDirs(extra []string) []string {
-	if len(extra) == 0 {
-		out := make([]string, len(defaultExcludeDirs))
-		copy(out, defaultExcludeDirs)
-		return out
-	}
-
-	seen := make(map[string]struct{}, len(defaultExcludeDirs)+len(extra))
-	var merged []string
-
-	for _, d := range defaultExcludeDirs {
-		if _, ok := seen[d]; !ok {
-			seen[d] = struct{}{}
-			merged = append(merged, d)
-		}
-	}
-
-	for _, d := range extra {
-		if _, ok := seen[d]; !ok {
-			seen[d] = struct{}{}
-			merged = append(merged, d)
-		}
-	}
-
-	return merged
-}
-
-// scanDirectory walks the target directory and applies the supplied patterns,
-// returning structured matches and per-file counts. This function is intentionally
-// UI-agnostic so it can be reused by both the TUI and JSON output paths.
-func scanDirectory(opts scanOptions) scanResult {
-	result := scanResult{
-		MatchFiles: make(map[string]int),
-	}
-
-	root := os.DirFS(opts.Directory)
-	excludeDirs := mergeExcludeDirs(opts.ExcludeDirs)
-
-	// Collect the list of files to scan.
-	var filenames []string
-	_ = fs.WalkDir(root, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil || d.IsDir() {
-			return nil
-		}
-		if !hasExtension(path, opts.Extensions) {
-			return nil
-		}
-
-		parts := strings.Split(path, "/")
-		for _, excludeDir := range excludeDirs {
-			if slices.Contains(parts, excludeDir) {
-				return nil
-			}
-		}
-
-		filenames = append(filenames, path)
-		return nil
-	})
-
-	result.Filenames = filenames
-
-	var (
-		mu        sync.Mutex
-		semaphore = make(chan struct{}, runtime.NumCPU())
-		wg        sync.WaitGroup
-	)
-
-	for i, path := range filenames {
-		semaphore <- struct{}{}
-		wg.Add(1)
-
-		if opts.OnCurrentFile != nil {
-			opts.OnCurrentFile(path, i, len(filenames))
-		}
-
-		go func(path string) {
-			defer wg.Done()
-			defer func() { <-semaphore }()
-
-			f, err := root.Open(path) // https://go.dev/blog/loopvar-preview
-			if err != nil {
-				panic(fmt.Errorf("unable to open file %s: %w", path, err))
-			}
-			defer func() {
-				if closeErr := f.Close(); closeErr != nil {
-					panic(fmt.Errorf("failed to close file %s: %w", path, closeErr))
-				}
-			}()
-
-			scanner := bufio.NewScanner(f)
-			lineNo := 0
-			for scanner.Scan() {
-				lineNo++
-				line := scanner.Text()
-				if len(line) <= 8 {
-					continue
-				}
-				if opts.FastPatterns.MatchString(line) {
-					if match := opts.SlowPatterns.FindString(line); match != "" && !opts.ExcludePatterns.MatchString(line) {
-						if opts.OnMatch != nil {
-							opts.OnMatch(path, lineNo, line, match)
-						}
-						mu.Lock()
-						result.Matches = append(result.Matches, Match{
-							File:        path,
-							Line:        lineNo,
-							LineSnippet: strings.TrimSpace(line),
-							MatchText:   match,
-						})
-						result.MatchFiles[path]++
-						mu.Unlock()
-					}
-				}
-			}
-		}(path)
+		return "", fmt.Errorf("invalid output format %q (supported: text, json, sarif)", format)
 	}
-
-	wg.Wait()
-	return result
 }
 
 // buildUsage constructs the primary usage/help text for the CLI.
 func buildUsage() string {
 	return `Usage: fasthog <directory> [flags]
+       fasthog <path>... [flags]
+       fasthog - [flags]
+       fasthog --staged [directory] [flags]
+       fasthog install-hook [directory] [--force]
 
 Flags:
   --types string     Comma-separated file extensions to include (e.g., yml,yaml,sh)
+  --filenames string Comma-separated file names or globs to include whatever
+                     their extension (e.g., Dockerfile,Jenkinsfile,.env.*)
+  --gitignore        Also skip paths matched by the repository's .gitignore files
+  --binary-strings   Scan the printable strings of binary files, as strings(1)
+                     extracts them, instead of skipping them
+  --no-decode        Do not rescan base64 and hex values that decode to text
+  --archive-depth int
+                     Levels of nested archives (zip, jar, tar, tar.gz) to scan
+                     inside; 0 disables archive scanning (default 3)
+  --archive-max-bytes int
+                     Most bytes read from a single archive, including nested
+                     ones (default 268435456)
+  --files-from string
+                     Also scan the newline-separated paths listed in a file
+                     (- for stdin)
   --output string    Path where output should be written
-  --format string    Output format: text or json (default "text")
+  --format string    Output format: text, json or sarif (default "text")
   --json             Shortcut for --format=json
   --config string    Path to config file (default: fasthog.yaml if present)
+  --fail-on string   Fail when findings reach a count (e.g. 5) or severity
+                     (low, medium, high, critical) (default "1")
+  --strict           Treat files that could not be scanned as a failure
+  --max-line-length int
+                     Bytes of a single line matched at once; longer lines are
+                     scanned in overlapping windows (default 65536)
+  --entropy          Also flag high-entropy quoted or assigned values
+  --entropy-base64-threshold float
+                     Minimum entropy for base64 values (default 4.5)
+  --entropy-hex-threshold float
+                     Minimum entropy for hex values (default 3)
+  --git-history      Scan lines added in the repository's commit history
+  --branch strings   Branch or revision to walk with --git-history
+                     (repeatable; default HEAD)
+  --since string     With --git-history, only commits more recent than a date
+  --until string     With --git-history, only commits older than a date
+  --staged           Scan only lines added in staged changes (directory
+                     defaults to the current one)
+  --force            With install-hook, replace an existing pre-commit hook
+  --no-redact        Show full secret values instead of masking them
+  --verbose          List matches not reported because their value is a
+                     placeholder, such as ${VAR} or changeme, with the reason
+  --baseline string  JSON file of accepted findings to suppress
+  --update-baseline  Write the current findings to the --baseline file
+
+Exit codes:
+  0  clean (no findings at or above --fail-on)
+  1  findings at or above --fail-on
+  2  usage or configuration error
+  3  scan error
 `
 }
 
@@ -298,14 +286,6 @@ func validateDirectory(directory string) error {
 	return nil
 }
 
-// PatternFiles describes optional overrides for the default regex pattern files.
-type PatternFiles struct {
-	Direct  string
-	Fast    string
-	Strict  string
-	Exclude string
-}
-
 // OutputConfig holds configuration related to the scan output.
 type OutputConfig struct {
 	Path   string
@@ -318,8 +298,9 @@ type OutputConfig struct {
 type Config struct {
 	Directory   string
 	Extensions  []string
+	Filenames   []string
 	ExcludeDirs []string
-	Patterns    PatternFiles
+	Patterns    fasthog.PatternFiles
 	Output      OutputConfig
 }
 
@@ -330,6 +311,9 @@ type Config struct {
 //	extensions:
 //	  - .go
 //	  - py
+//	filenames:
+//	  - Dockerfile
+//	  - .env.*
 //	exclude_dirs:
 //	  - build
 //	output:
@@ -368,6 +352,8 @@ func loadConfig(path string) (Config, error) {
 				cfg.Directory = value
 			case "extensions":
 				currentSection = "extensions"
+			case "filenames":
+				currentSection = "filenames"
 			case "exclude_dirs":
 				currentSection = "exclude_dirs"
 			case "output":
@@ -393,6 +379,13 @@ func loadConfig(path string) (Config, error) {
 					cfg.Extensions = append(cfg.Extensions, val)
 				}
 			}
+		case "filenames":
+			if strings.HasPrefix(trimmedIndent, "- ") {
+				val := strings.TrimSpace(strings.TrimPrefix(trimmedIndent, "- "))
+				if val != "" {
+					cfg.Filenames = append(cfg.Filenames, val)
+				}
+			}
 		case "exclude_dirs":
 			if strings.HasPrefix(trimmedIndent, "- ") {
 				val := strings.TrimSpace(strings.TrimPrefix(trimmedIndent, "- "))
@@ -461,7 +454,29 @@ func determineExtensions(extensionsFlag string, flagChanged bool, cfg Config) []
 		return append([]string(nil), cfg.Extensions...)
 	}
 
-	return defaultExtensions
+	return fasthog.DefaultExtensions
+}
+
+// determineFilenames applies the same precedence as determineExtensions to
+// the filename globs: CLI, then config, then defaultFilenames unless the
+// extensions were chosen explicitly.
+func determineFilenames(filenamesFlag string, flagChanged bool, cfg Config, explicitTypes bool) []string {
+	if flagChanged {
+		var filenames []string
+		for _, name := range strings.Split(filenamesFlag, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				filenames = append(filenames, name)
+			}
+		}
+		return filenames
+	}
+	if len(cfg.Filenames) > 0 {
+		return append([]string(nil), cfg.Filenames...)
+	}
+	if explicitTypes {
+		return nil
+	}
+	return fasthog.DefaultFilenames
 }
 
 // determineOutputFormat applies precedence between the --format flag,
@@ -499,109 +514,6 @@ func splitKeyValue(s string) (key, value string) {
 	return key, value
 }
 
-// loadEffectivePatterns loads the regex patterns used for scanning, applying
-// any file overrides specified in patternFiles. When no overrides are
-// provided, the embedded default patterns are used.
-func loadEffectivePatterns(patternFiles PatternFiles) (exclude, fast, slow *regexp.Regexp, err error) {
-	overrideFS := os.DirFS(".")
-
-	// Exclude patterns.
-	if patternFiles.Exclude != "" {
-		exclude, err = loadRegexes(overrideFS, patternFiles.Exclude)
-		if err != nil {
-			err = fmt.Errorf("failed to load exclude patterns from %s: %w", patternFiles.Exclude, err)
-			return
-		}
-	} else {
-		exclude, err = loadRegexes(regexFS, "exclude_patterns.regex")
-		if err != nil {
-			err = fmt.Errorf("failed to load exclude patterns: %w", err)
-			return
-		}
-	}
-
-	// Fast patterns.
-	if patternFiles.Fast != "" {
-		fast, err = loadRegexes(overrideFS, patternFiles.Fast)
-		if err != nil {
-			err = fmt.Errorf("failed to load fast patterns from %s: %w", patternFiles.Fast, err)
-			return
-		}
-	} else {
-		fast, err = loadRegexes(regexFS, "direct_matches.regex", "fast_patterns.regex")
-		if err != nil {
-			err = fmt.Errorf("failed to load fast patterns: %w", err)
-			return
-		}
-	}
-
-	// Slow (strict) patterns. If either direct or strict overrides are supplied,
-	// they fully replace the embedded slow patterns.
-	if patternFiles.Direct != "" || patternFiles.Strict != "" {
-		var paths []string
-		if patternFiles.Direct != "" {
-			paths = append(paths, patternFiles.Direct)
-		}
-		if patternFiles.Strict != "" {
-			paths = append(paths, patternFiles.Strict)
-		}
-
-		slow, err = loadRegexes(overrideFS, paths...)
-		if err != nil {
-			err = fmt.Errorf("failed to load strict patterns from override files: %w", err)
-			return
-		}
-	} else {
-		slow, err = loadRegexes(regexFS, "direct_matches.regex", "strict_patterns.regex")
-		if err != nil {
-			err = fmt.Errorf("failed to load strict patterns: %w", err)
-			return
-		}
-	}
-
-	return
-}
-
-// shellReplacer replaces bash escape sequences in regex files to maintain
-// compatibility with the original shell script implementation.
-var shellReplacer = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
-
-// loadRegexes loads and compiles regular expressions from one or more embedded files.
-// It combines all patterns into a single compiled regex for efficient matching.
-// Lines starting with '#' are treated as comments and ignored.
-func loadRegexes(filesystem fs.FS, paths ...string) (*regexp.Regexp, error) {
-	var regexes string
-	for _, path := range paths {
-		b, err := fs.ReadFile(filesystem, path)
-		if err != nil {
-			return nil, fmt.Errorf("unable to load regexes from %s: %w", path, err)
-		}
-		for _, line := range bytes.Split(b, []byte("\n")) {
-			if len(line) == 0 || line[0] == '#' {
-				continue
-			}
-			if len(regexes) > 0 {
-				regexes += "|"
-			}
-			regexes += "(" + string(line) + ")"
-		}
-	}
-
-	regexes = shellReplacer.Replace(regexes)
-	compiled, err := regexp.Compile(regexes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to compile regex patterns: %w", err)
-	}
-	return compiled, nil
-}
-
-// hasExtension checks if a file path has one of the specified extensions.
-func hasExtension(path string, extensions []string) bool {
-	return slices.ContainsFunc(extensions, func(ext string) bool {
-		return strings.HasSuffix(strings.ToLower(path), ext)
-	})
-}
-
 // UI message types for the Bubble Tea interface.
 
 // msgCurrentFile indicates progress through the file list.
@@ -672,8 +584,11 @@ func main() {
 	var extensionsList string
 	pflag.StringVar(&extensionsList, "types", "", "Comma-separated list of file extensions to include (e.g., yml,yaml,sh)")
 
+	var filenamesList string
+	pflag.StringVar(&filenamesList, "filenames", "", "Comma-separated list of file names or globs to include whatever their extension (e.g., Dockerfile,.envrc)")
+
 	var formatFlag string
-	pflag.StringVar(&formatFlag, "format", string(OutputFormatText), "Output format: text or json")
+	pflag.StringVar(&formatFlag, "format", string(OutputFormatText), "Output format: text, json or sarif")
 
 	var jsonFlag bool
 	pflag.BoolVar(&jsonFlag, "json", false, "Shortcut for --format=json")
@@ -681,16 +596,154 @@ func main() {
 	var configPath string
 	pflag.StringVar(&configPath, "config", "", "Path to configuration file (YAML; optional)")
 
+	var strictFlag bool
+	pflag.BoolVar(&strictFlag, "strict", false, "Treat files that could not be scanned as a failure")
+
+	var maxLineLength int
+	pflag.IntVar(&maxLineLength, "max-line-length", fasthog.DefaultMaxLineLength, "Bytes of a single line matched at once; longer lines are scanned in overlapping windows")
+
+	entropy := fasthog.DefaultEntropyOptions()
+	pflag.BoolVar(&entropy.Enabled, "entropy", false, "Also flag high-entropy quoted or assigned values")
+	pflag.Float64Var(&entropy.Base64Threshold, "entropy-base64-threshold", entropy.Base64Threshold, "Minimum entropy (bits per char) for base64 values with --entropy")
+	pflag.Float64Var(&entropy.HexThreshold, "entropy-hex-threshold", entropy.HexThreshold, "Minimum entropy (bits per char) for hex values with --entropy")
+
+	var (
+		gitHistory bool
+		history    fasthog.GitHistoryOptions
+	)
+	pflag.BoolVar(&gitHistory, "git-history", false, "Scan lines added in the repository's commit history instead of the working tree")
+	pflag.StringSliceVar(&history.Branches, "branch", nil, "Branch or revision whose history --git-history walks (repeatable; default HEAD)")
+	pflag.StringVar(&history.Since, "since", "", "With --git-history, only scan commits more recent than this date")
+	pflag.StringVar(&history.Until, "until", "", "With --git-history, only scan commits older than this date")
+
+	var staged bool
+	pflag.BoolVar(&staged, "staged", false, "Scan only the lines added in changes staged for commit")
+
+	var forceHook bool
+	pflag.BoolVar(&forceHook, "force", false, "With install-hook, replace an existing pre-commit hook")
+
+	var noRedact bool
+	pflag.BoolVar(&noRedact, "no-redact", false, "Show full secret values in all output instead of masking them")
+
+	var verbose bool
+	pflag.BoolVar(&verbose, "verbose", false, "List the matches not reported because their value is a placeholder, with the reason")
+
+	var baselinePath string
+	pflag.StringVar(&baselinePath, "baseline", "", "JSON file of previously reviewed findings to suppress")
+
+	var updateBaseline bool
+	pflag.BoolVar(&updateBaseline, "update-baseline", false, "Write the current findings to the --baseline file")
+
+	var gitignore bool
+	pflag.BoolVar(&gitignore, "gitignore", false, "Also skip paths matched by the repository's .gitignore files")
+
+	var binaryStrings bool
+	pflag.BoolVar(&binaryStrings, "binary-strings", false, "Scan the printable strings of binary files instead of skipping them")
+
+	var noDecode bool
+	pflag.BoolVar(&noDecode, "no-decode", false, "Do not rescan base64 and hex values that decode to text")
+
+	archives := fasthog.DefaultArchiveOptions()
+	pflag.IntVar(&archives.MaxDepth, "archive-depth", archives.MaxDepth, "Levels of nested archives (zip, jar, tar, tar.gz) to scan inside; 0 disables archive scanning")
+	pflag.Int64Var(&archives.MaxBytes, "archive-max-bytes", archives.MaxBytes, "Most bytes read from a single archive, including nested ones")
+
+	var filesFrom string
+	pflag.StringVar(&filesFrom, "files-from", "", "Also scan the newline-separated paths listed in this file (- for stdin)")
+
+	var failOnFlag string
+	pflag.StringVar(&failOnFlag, "fail-on", defaultFailOn, "Fail when findings reach a count (e.g. 5) or severity (low, medium, high, critical)")
+
 	pflag.Parse()
 
 	remainingArgs := pflag.Args()
 
-	if len(remainingArgs) < 1 {
+	if len(remainingArgs) > 0 && remainingArgs[0] == "install-hook" {
+		hookDir := "."
+		if len(remainingArgs) > 1 {
+			hookDir = remainingArgs[1]
+		}
+		path, err := installHook(hookDir, forceHook)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+		fmt.Printf("Installed pre-commit hook: %s\n", path)
+		os.Exit(exitClean)
+	}
+
+	if forceHook {
+		fmt.Fprintln(os.Stderr, "--force requires install-hook")
+		os.Exit(exitUsage)
+	}
+
+	// Pre-commit hooks run from the repository root, so --staged defaults to
+	// the current directory.
+	if staged && len(remainingArgs) == 0 {
+		remainingArgs = []string{"."}
+	}
+
+	targets := remainingArgs
+	if filesFrom != "" {
+		if filesFrom == fasthog.StdinTarget && slices.Contains(targets, fasthog.StdinTarget) {
+			fmt.Fprintln(os.Stderr, "--files-from=- cannot be combined with - as a scan target")
+			os.Exit(exitUsage)
+		}
+		listed, err := readFileList(filesFrom)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(exitUsage)
+		}
+		targets = append(slices.Clone(targets), listed...)
+	}
+
+	if len(targets) < 1 {
 		fmt.Println(buildUsage())
-		os.Exit(1)
+		os.Exit(exitUsage)
+	}
+
+	failOn, err := parseFailOn(failOnFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(exitUsage)
 	}
 
-	directory := remainingArgs[0]
+	if maxLineLength < fasthog.MinMaxLineLength {
+		fmt.Fprintf(os.Stderr, "invalid --max-line-length %d (must be at least %d)\n", maxLineLength, fasthog.MinMaxLineLength)
+		os.Exit(exitUsage)
+	}
+
+	if archives.MaxDepth < 0 || archives.MaxBytes <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid archive limits: --archive-depth %d must not be negative and --archive-max-bytes %d must be positive\n", archives.MaxDepth, archives.MaxBytes)
+		os.Exit(exitUsage)
+	}
+
+	if updateBaseline && baselinePath == "" {
+		fmt.Fprintln(os.Stderr, "--update-baseline requires --baseline")
+		os.Exit(exitUsage)
+	}
+
+	if staged && gitHistory {
+		fmt.Fprintln(os.Stderr, "--staged and --git-history cannot be combined")
+		os.Exit(exitUsage)
+	}
+
+	if !gitHistory {
+		for _, name := range []string{"branch", "since", "until"} {
+			if pflag.Lookup(name).Changed {
+				fmt.Fprintf(os.Stderr, "--%s requires --git-history\n", name)
+				os.Exit(exitUsage)
+			}
+		}
+	}
+
+	// A single directory keeps paths relative to it; anything else, and
+	// --files-from even if it lists nothing, is a list of targets.
+	var directory string
+	if len(targets) == 1 && filesFrom == "" && targets[0] != fasthog.StdinTarget {
+		if info, err := os.Stat(targets[0]); err != nil || info.IsDir() {
+			directory, targets = targets[0], nil
+		}
+	}
 
 	// Load configuration file, if any.
 	var fileCfg Config
@@ -698,7 +751,7 @@ func main() {
 		cfg, err := loadConfig(configPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error loading config file %s: %v\n", configPath, err)
-			os.Exit(1)
+			os.Exit(exitUsage)
 		}
 		fileCfg = cfg
 	} else {
@@ -706,7 +759,7 @@ func main() {
 			cfg, err := loadConfig("fasthog.yaml")
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error loading config file fasthog.yaml: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitUsage)
 			}
 			fileCfg = cfg
 		}
@@ -715,6 +768,11 @@ func main() {
 	// Determine extensions: CLI > config > defaults.
 	extensions := determineExtensions(extensionsList, pflag.Lookup("types").Changed, fileCfg)
 
+	// Determine filenames: CLI > config > defaults. Explicitly chosen types
+	// replace the default filenames and extensionless sniffing too.
+	explicitTypes := pflag.Lookup("types").Changed || len(fileCfg.Extensions) > 0
+	filenames := determineFilenames(filenamesList, pflag.Lookup("filenames").Changed, fileCfg, explicitTypes)
+
 	// Determine additional excluded directories from config.
 	excludeDirs := append([]string(nil), fileCfg.ExcludeDirs...)
 
@@ -728,68 +786,291 @@ func main() {
 	)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	// Determine output path: CLI > config.
 	outputPath = determineOutputPath(outputPath, pflag.Lookup("output").Changed, fileCfg)
 
 	if outputFormat == OutputFormatText {
-		fmt.Printf("Directory: %s\n", directory)
-		if pflag.Lookup("types").Changed || len(fileCfg.Extensions) > 0 {
+		if directory != "" {
+			fmt.Printf("Directory: %s\n", directory)
+		} else {
+			fmt.Printf("Targets: %s\n", strings.Join(targets, ", "))
+		}
+		if gitHistory {
+			revs, _ := history.Revisions()
+			fmt.Printf("Git history: %s\n", strings.Join(revs, ", "))
+		}
+		if staged {
+			fmt.Println("Staged changes only")
+		}
+		if explicitTypes {
 			fmt.Printf("Extensions: %v\n", extensions)
 		} else {
 			fmt.Println("Extensions: Using defaults")
 		}
+		if pflag.Lookup("filenames").Changed || len(fileCfg.Filenames) > 0 {
+			fmt.Printf("Filenames: %v\n", filenames)
+		}
 		if outputPath != "" {
 			fmt.Printf("Output: %s\n", outputPath)
 		}
 	}
 
-	var runErr error
+	runOpts := runOptions{
+		Directory:          directory,
+		Targets:            targets,
+		Extensions:         extensions,
+		ExcludeDirs:        excludeDirs,
+		Filenames:          filenames,
+		SniffExtensionless: !explicitTypes,
+		BinaryStrings:      binaryStrings,
+		NoDecode:           noDecode,
+		Archives:           archives,
+		PatternFiles:       fileCfg.Patterns,
+		OutputPath:         outputPath,
+		MaxLineLength:      maxLineLength,
+		Entropy:            entropy,
+		Staged:             staged,
+		NoRedact:           noRedact,
+		Verbose:            verbose,
+		Gitignore:          gitignore,
+
+		BaselinePath:   baselinePath,
+		UpdateBaseline: updateBaseline,
+	}
+	if gitHistory {
+		runOpts.History = &history
+	}
+
+	var (
+		scanRes fasthog.Result
+		runErr  error
+	)
 	switch outputFormat {
 	case OutputFormatJSON:
-		runErr = runFasthogJSON(directory, extensions, excludeDirs, fileCfg.Patterns, outputPath)
+		scanRes, runErr = runFasthogJSON(runOpts)
+	case OutputFormatSARIF:
+		scanRes, runErr = runFasthogSARIF(runOpts)
 	case OutputFormatText:
 		fallthrough
 	default:
-		runErr = runFasthog(directory, extensions, excludeDirs, fileCfg.Patterns, outputPath)
+		scanRes, runErr = runFasthog(runOpts)
 	}
 
 	if runErr != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", runErr)
-		os.Exit(1)
+		os.Exit(exitCodeForError(runErr))
+	}
+
+	if strictFlag && len(scanRes.Errors) > 0 {
+		os.Exit(exitScanError)
 	}
+	// Updating the baseline accepts every current finding.
+	if !updateBaseline && failOn.exceeded(scanRes.Matches) {
+		os.Exit(exitFindings)
+	}
+	os.Exit(exitClean)
 }
 
-// runFasthogJSON executes the secrets scanning process and emits JSON output.
-// It is intentionally non-interactive: no TUI, no ANSI, and only JSON on stdout.
-func runFasthogJSON(directory string, extensions []string, excludeDirs []string, patternFiles PatternFiles, outputPath string) error {
-	if err := validateDirectory(directory); err != nil {
+// runOptions holds the resolved CLI and config settings for a single run,
+// shared by the text and JSON output paths.
+type runOptions struct {
+	Directory string
+	// Targets, if non-empty, lists the files, directories and "-" for
+	// standard input to scan instead of Directory.
+	Targets     []string
+	Extensions  []string
+	ExcludeDirs []string
+	Filenames   []string
+	// SniffExtensionless scans extensionless files that look like text.
+	SniffExtensionless bool
+	// BinaryStrings scans the printable strings of binary files.
+	BinaryStrings bool
+	// NoDecode skips rescanning decoded base64 and hex values.
+	NoDecode      bool
+	Archives      fasthog.ArchiveOptions
+	PatternFiles  fasthog.PatternFiles
+	OutputPath    string
+	MaxLineLength int
+	Entropy       fasthog.EntropyOptions
+	// History, if non-nil, scans the lines added in the repository's commit
+	// history rather than the working tree.
+	History *fasthog.GitHistoryOptions
+	// Staged scans only the lines added in changes staged for commit.
+	Staged bool
+	// Gitignore skips paths matched by .gitignore files.
+	Gitignore bool
+	// NoRedact shows full secret values in output instead of masking all
+	// but a short prefix and suffix.
+	NoRedact bool
+	// Verbose lists the matches dropped as placeholders, with their reason.
+	Verbose bool
+	// BaselinePath names the baseline file. Its findings are suppressed,
+	// unless UpdateBaseline is set, in which case every finding is reported
+	// and the file is rewritten with them.
+	BaselinePath   string
+	UpdateBaseline bool
+}
+
+// validate checks the scan target before any output is produced.
+func (ro runOptions) validate() error {
+	if len(ro.Targets) > 0 {
+		if ro.History != nil || ro.Staged {
+			return errors.New("--git-history and --staged scan a single directory")
+		}
+		return fasthog.ValidateTargets(ro.Targets)
+	}
+	if err := validateDirectory(ro.Directory); err != nil {
 		return err
 	}
+	if ro.History != nil {
+		return fasthog.ValidateGitHistory(ro.Directory, *ro.History)
+	}
+	if ro.Staged {
+		return fasthog.ValidateGitRepo(ro.Directory, "--staged")
+	}
+	return nil
+}
+
+// loadBaseline loads the baseline whose findings are suppressed, or returns
+// nil if none applies.
+func (ro runOptions) loadBaseline() (fasthog.Baseline, error) {
+	if ro.BaselinePath == "" || ro.UpdateBaseline {
+		return nil, nil
+	}
+	return fasthog.LoadBaseline(ro.BaselinePath)
+}
 
-	excludePatterns, fastPatterns, slowPatterns, err := loadEffectivePatterns(patternFiles)
+// saveBaseline rewrites the baseline file with the findings of scanRes when
+// UpdateBaseline is set.
+func (ro runOptions) saveBaseline(scanRes fasthog.Result) error {
+	if !ro.UpdateBaseline {
+		return nil
+	}
+	return fasthog.WriteBaseline(ro.BaselinePath, scanRes.Matches)
+}
+
+// newScanner builds the scanner for ro. onFile and onMatch, if non-nil,
+// report progress as in fasthog.Options.
+func (ro runOptions) newScanner(onFile func(path string, index, total int), onMatch func(lm fasthog.LineMatches)) (*fasthog.Scanner, error) {
+	patterns, err := fasthog.LoadPatterns(ro.PatternFiles)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	startedAt := time.Now().UTC()
+	accepted, err := ro.loadBaseline()
+	if err != nil {
+		return nil, err
+	}
+
+	return fasthog.New(patterns, fasthog.Options{
+		Extensions:         ro.Extensions,
+		ExcludeDirs:        ro.ExcludeDirs,
+		Filenames:          ro.Filenames,
+		SniffExtensionless: ro.SniffExtensionless,
+		BinaryStrings:      ro.BinaryStrings,
+		Decode:             !ro.NoDecode,
+		Archives:           ro.Archives,
+		Gitignore:          ro.Gitignore,
+		Entropy:            ro.Entropy,
+		Baseline:           accepted,
+		MaxLineLength:      ro.MaxLineLength,
+		OnFile:             onFile,
+		OnMatch:            onMatch,
+	})
+}
+
+// scan runs the scan selected by ro with scanner.
+func (ro runOptions) scan(scanner *fasthog.Scanner) (fasthog.Result, error) {
+	ctx := context.Background()
+	if ro.History != nil {
+		return scanner.ScanGitHistory(ctx, ro.Directory, *ro.History)
+	}
+	if ro.Staged {
+		return scanner.ScanStaged(ctx, ro.Directory)
+	}
+	if len(ro.Targets) > 0 {
+		return scanner.ScanPaths(ctx, ro.Targets)
+	}
+	return scanner.ScanDirectory(ctx, ro.Directory)
+}
 
-	opts := scanOptions{
-		Directory:       directory,
-		Extensions:      extensions,
-		ExcludeDirs:     excludeDirs,
-		ExcludePatterns: excludePatterns,
-		FastPatterns:    fastPatterns,
-		SlowPatterns:    slowPatterns,
+// sourceRoot returns the absolute directory that the paths of findings are
+// relative to: the scanned directory or repository, or the working directory
+// for targets. It returns "" if the directory cannot be resolved.
+func (ro runOptions) sourceRoot() string {
+	dir := ro.Directory
+	if len(ro.Targets) > 0 && ro.History == nil && !ro.Staged {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
 	}
+	return abs
+}
 
-	scanRes := scanDirectory(opts)
+// runQuiet validates ro and runs its scan without the TUI, as required by
+// the machine-readable output formats. It returns the scanner used, so that
+// callers can describe the active rules.
+func runQuiet(ro runOptions) (*fasthog.Scanner, fasthog.Result, error) {
+	if err := ro.validate(); err != nil {
+		return nil, fasthog.Result{}, usageError{err}
+	}
+
+	scanner, err := ro.newScanner(nil, nil)
+	if err != nil {
+		return nil, fasthog.Result{}, usageError{err}
+	}
+
+	scanRes, err := ro.scan(scanner)
+	if err != nil {
+		return scanner, scanRes, fmt.Errorf("scan failed: %w", err)
+	}
+	if err := ro.saveBaseline(scanRes); err != nil {
+		return scanner, scanRes, err
+	}
+	return scanner, scanRes, nil
+}
+
+// writeReport writes machine-readable output to stdout, where it must be the
+// only output, and to ro.OutputPath if set. name labels errors, e.g. "JSON".
+func (ro runOptions) writeReport(name string, data []byte) error {
+	data = append(data, '\n')
+	if _, err := os.Stdout.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to stdout: %w", name, err)
+	}
+
+	if ro.OutputPath != "" {
+		if err := os.WriteFile(ro.OutputPath, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s output to %s: %w", name, ro.OutputPath, err)
+		}
+	}
+	return nil
+}
+
+// runFasthogJSON executes the secrets scanning process and emits JSON output.
+// It is intentionally non-interactive: no TUI, no ANSI, and only JSON on stdout.
+// The scan result is returned so the caller can decide the exit code.
+func runFasthogJSON(ro runOptions) (fasthog.Result, error) {
+	startedAt := time.Now().UTC()
+
+	_, scanRes, err := runQuiet(ro)
+	if err != nil {
+		return scanRes, err
+	}
 
 	summary := ScanSummary{
 		TotalMatches:      len(scanRes.Matches),
 		TotalFilesScanned: len(scanRes.Filenames),
+		TotalErrors:       len(scanRes.Errors),
+		TotalWarnings:     len(scanRes.Warnings),
+		TotalSuppressed:   scanRes.Suppressed,
+		TotalIgnored:      scanRes.Ignored,
+		TotalSkipped:      scanRes.Skipped,
+		TotalPlaceholders: len(scanRes.Placeholders),
 	}
 	for _, count := range scanRes.MatchFiles {
 		if count > 0 {
@@ -808,94 +1089,127 @@ func runFasthogJSON(directory string, extensions []string, excludeDirs []string,
 		return topFiles[i].Count > topFiles[j].Count
 	})
 
+	matches := scanRes.Matches
+	if !ro.NoRedact {
+		matches = fasthog.RedactMatches(matches)
+	}
+
+	// Errors and warnings are copied into non-nil slices, so that a clean
+	// scan lists none rather than null.
 	result := JSONResult{
-		Directory:  directory,
-		Extensions: extensions,
+		Directory:  ro.Directory,
+		Targets:    ro.Targets,
+		Extensions: ro.Extensions,
 		StartTime:  startedAt,
 		DurationMs: time.Since(startedAt).Milliseconds(),
-		Matches:    scanRes.Matches,
+		Matches:    matches,
+		Errors:     append([]fasthog.ScanError{}, scanRes.Errors...),
+		Warnings:   append([]fasthog.ScanWarning{}, scanRes.Warnings...),
 		Summary:    summary,
 		TopFiles:   topFiles,
 	}
+	if ro.Verbose {
+		result.Placeholders = scanRes.Placeholders
+	}
 
 	data, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to encode JSON output: %w", err)
+		return scanRes, fmt.Errorf("failed to encode JSON output: %w", err)
 	}
+	return scanRes, ro.writeReport("JSON", data)
+}
 
-	// Write JSON to stdout. This must be the only output in JSON mode.
-	if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
-		return fmt.Errorf("failed to write JSON to stdout: %w", err)
+// runFasthogSARIF executes the secrets scanning process and emits a SARIF
+// 2.1.0 log, non-interactively like runFasthogJSON.
+func runFasthogSARIF(ro runOptions) (fasthog.Result, error) {
+	scanner, scanRes, err := runQuiet(ro)
+	if err != nil {
+		return scanRes, err
 	}
 
-	if outputPath != "" {
-		if err := os.WriteFile(outputPath, append(data, '\n'), 0o644); err != nil {
-			return fmt.Errorf("failed to write JSON output to %s: %w", outputPath, err)
-		}
+	data, err := json.MarshalIndent(buildSARIF(scanner.Rules(), scanRes, ro.sourceRoot()), "", "  ")
+	if err != nil {
+		return scanRes, fmt.Errorf("failed to encode SARIF output: %w", err)
 	}
-
-	return nil
+	return scanRes, ro.writeReport("SARIF", data)
 }
 
 // runFasthog executes the secrets scanning process on the specified directory.
-// It returns an error if the scan fails.
-func runFasthog(directory string, extensions []string, excludeDirs []string, patternFiles PatternFiles, outputPath string) error {
+// It returns the scan result, or an error if the scan fails.
+func runFasthog(ro runOptions) (fasthog.Result, error) {
 	start := time.Now()
 
-	if err := validateDirectory(directory); err != nil {
-		return err
+	if err := ro.validate(); err != nil {
+		return fasthog.Result{}, usageError{err}
 	}
 
-	excludePatterns, fastPatterns, slowPatterns, err := loadEffectivePatterns(patternFiles)
-	if err != nil {
-		return err
+	var teaOpts []tea.ProgramOption
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		// Without a terminal on stdin, as in git hooks, Bubble Tea would try
+		// to open /dev/tty and fail where there is none.
+		teaOpts = append(teaOpts, tea.WithInput(nil))
 	}
-
 	p := tea.NewProgram(model{
 		progress: progress.New(progress.WithDefaultGradient()),
-	})
+	}, teaOpts...)
 
 	var (
 		matches []string
 		mu      sync.Mutex
 	)
 
-	resultsCh := make(chan scanResult, 1)
+	onFile := func(path string, index, total int) {
+		percent := 0.0
+		if total > 0 {
+			percent = float64(index) / float64(total)
+		}
+		p.Send(msgCurrentFile{path: path, percent: percent})
+	}
+	onMatch := func(lm fasthog.LineMatches) {
+		location := styleFile(lm.Path)
+		if commit := lm.Matches[0].Commit; commit != nil {
+			location = styleLineNo(commit.ShortSHA()+":") + location
+		}
+		var styled string
+		if lm.Matches[0].IsFileFinding() {
+			styled = styleMatch(lm.Text)
+		} else {
+			location += styleLineNo(fmt.Sprintf(":%.4d", lm.Line))
+			styled = strings.TrimSpace(highlightMatches(lm, !ro.NoRedact))
+		}
+		mu.Lock()
+		matches = append(matches, location+" "+styled)
+		mu.Unlock()
+		for range lm.Matches {
+			p.Send(msgMatch{})
+		}
+	}
+	scanner, err := ro.newScanner(onFile, onMatch)
+	if err != nil {
+		return fasthog.Result{}, usageError{err}
+	}
+
+	type scanOutcome struct {
+		res fasthog.Result
+		err error
+	}
+	resultsCh := make(chan scanOutcome, 1)
 
 	go func() {
-		opts := scanOptions{
-			Directory:       directory,
-			Extensions:      extensions,
-			ExcludeDirs:     excludeDirs,
-			ExcludePatterns: excludePatterns,
-			FastPatterns:    fastPatterns,
-			SlowPatterns:    slowPatterns,
-			OnCurrentFile: func(path string, index, total int) {
-				percent := 0.0
-				if total > 0 {
-					percent = float64(index) / float64(total)
-				}
-				p.Send(msgCurrentFile{path: path, percent: percent})
-			},
-			OnMatch: func(path string, lineNo int, line, match string) {
-				styled := strings.TrimSpace(strings.Replace(line, match, styleMatch(match), 1))
-				mu.Lock()
-				matches = append(matches, styleFile(path)+styleLineNo(fmt.Sprintf(":%.4d", lineNo))+" "+styled)
-				mu.Unlock()
-				p.Send(msgMatch{})
-			},
-		}
-
-		scanRes := scanDirectory(opts)
-		resultsCh <- scanRes
+		scanRes, err := ro.scan(scanner)
+		resultsCh <- scanOutcome{scanRes, err}
 		p.Send(msgDone{})
 	}()
 
 	if _, err := p.Run(); err != nil {
-		return fmt.Errorf("UI error: %w", err)
+		return fasthog.Result{}, fmt.Errorf("UI error: %w", err)
 	}
 
-	scanRes := <-resultsCh
+	outcome := <-resultsCh
+	if outcome.err != nil {
+		return outcome.res, fmt.Errorf("scan failed: %w", outcome.err)
+	}
+	scanRes := outcome.res
 	filenames := scanRes.Filenames
 
 	fmt.Println("\nResults:")
@@ -933,17 +1247,118 @@ func runFasthog(directory string, extensions []string, excludeDirs []string, pat
 		}
 	}
 
+	if len(scanRes.Warnings) > 0 {
+		fmt.Println("\nWarnings:")
+		for _, warning := range scanRes.Warnings {
+			fmt.Println(warning.String())
+		}
+	}
+
+	if ro.Verbose && len(scanRes.Placeholders) > 0 {
+		fmt.Println("\nPlaceholders:")
+		for _, p := range scanRes.Placeholders {
+			fmt.Println(p.String())
+		}
+	}
+
+	if len(scanRes.Errors) > 0 {
+		fmt.Println("\nErrors:")
+		for _, scanErr := range scanRes.Errors {
+			fmt.Println(scanErr.Error())
+		}
+	}
+
 	fmt.Printf("\nCompleted in %s: %d matches across %d of %d files\n",
-		time.Since(start).Truncate(time.Millisecond), len(matches), filesWithMatches, len(filenames))
+		time.Since(start).Truncate(time.Millisecond), len(scanRes.Matches), filesWithMatches, len(filenames))
+	if len(scanRes.Errors) > 0 {
+		fmt.Printf("%d paths could not be scanned (see Errors above)\n", len(scanRes.Errors))
+	}
+	if scanRes.Suppressed > 0 {
+		fmt.Printf("%d findings suppressed by baseline %s\n", scanRes.Suppressed, ro.BaselinePath)
+	}
+	if scanRes.Ignored > 0 {
+		fmt.Printf("%d findings ignored by fasthog:ignore comments\n", scanRes.Ignored)
+	}
+	if scanRes.Skipped > 0 {
+		fmt.Printf("%d binary files skipped (scan them with --binary-strings)\n", scanRes.Skipped)
+	}
+	if n := len(scanRes.Placeholders); n > 0 {
+		hint := "list them with --verbose"
+		if ro.Verbose {
+			hint = placeholderCounts(scanRes.Placeholders)
+		}
+		fmt.Printf("%d placeholder values not reported (%s)\n", n, hint)
+	}
 
-	if outputPath != "" {
-		if err := writeResults(matches, outputPath); err != nil {
-			return fmt.Errorf("failed to write results: %w", err)
+	if err := ro.saveBaseline(scanRes); err != nil {
+		return scanRes, err
+	}
+	if ro.UpdateBaseline {
+		fmt.Printf("Baseline written to %s\n", ro.BaselinePath)
+	}
+
+	if ro.OutputPath != "" {
+		if err := writeResults(matches, ro.OutputPath); err != nil {
+			return scanRes, fmt.Errorf("failed to write results: %w", err)
 		}
-		fmt.Printf("Results written to %s\n", outputPath)
+		fmt.Printf("Results written to %s\n", ro.OutputPath)
 	}
 
-	return nil
+	return scanRes, nil
+}
+
+// placeholderCounts summarizes placeholders by reason, most frequent first,
+// e.g. "3 shell-variable, 1 empty".
+func placeholderCounts(placeholders []fasthog.Placeholder) string {
+	counts := make(map[string]int)
+	for _, p := range placeholders {
+		counts[p.Reason]++
+	}
+	reasons := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+	parts := make([]string, len(reasons))
+	for i, r := range reasons {
+		parts[i] = fmt.Sprintf("%d %s", counts[r], r)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// highlightMatches renders lm.Text with every match styled, using the match
+// column offsets so that repeated or overlapping text is highlighted at the
+// right place. With redact, the secret within each match is masked, as are
+// values assigned to a matched key name outside the match.
+func highlightMatches(lm fasthog.LineMatches, redact bool) string {
+	var b strings.Builder
+	plain := func(text string) string {
+		if redact {
+			return fasthog.RedactText(text, lm.Matches)
+		}
+		return text
+	}
+	pos := 0
+	for _, m := range lm.Matches {
+		from, to := m.Column-1-lm.Offset, m.EndColumn-1-lm.Offset
+		if m.EndLine > m.Line {
+			// Multi-line findings are highlighted to the end of their first line.
+			to = len(lm.Text)
+		}
+		if from < pos || to > len(lm.Text) {
+			continue
+		}
+		b.WriteString(plain(lm.Text[pos:from]))
+		text := lm.Text[from:to]
+		if redact && m.EndLine <= m.Line {
+			text = fasthog.RedactMatchText(m)
+		}
+		b.WriteString(styleMatch(text))
+		pos = to
+	}
+	b.WriteString(plain(lm.Text[pos:]))
+	return b.String()
 }
 
 // writeResults writes scan results to a file, stripping ANSI color codes.
@@ -6,117 +6,6 @@ import (
 	"testing"
 )
 
-// BenchmarkLoadRegexes measures regex loading and compilation performance.
-func BenchmarkLoadRegexes(b *testing.B) {
-	b.Run("exclude_patterns", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_, err := loadRegexes(regexFS, "exclude_patterns.regex")
-			if err != nil {
-				b.Fatal(err)
-			}
-		}
-	})
-
-	b.Run("fast_patterns", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_, err := loadRegexes(regexFS, "direct_matches.regex", "fast_patterns.regex")
-			if err != nil {
-				b.Fatal(err)
-			}
-		}
-	})
-
-	b.Run("strict_patterns", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_, err := loadRegexes(regexFS, "direct_matches.regex", "strict_patterns.regex")
-			if err != nil {
-				b.Fatal(err)
-			}
-		}
-	})
-}
-
-// BenchmarkHasExtension measures extension matching performance.
-func BenchmarkHasExtension(b *testing.B) {
-	extensions := defaultExtensions
-	paths := []string{
-		"test.py",
-		"test.js",
-		"test.go",
-		"test.txt",
-		"test.unknown",
-		"very/long/path/to/some/file.py",
-	}
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, path := range paths {
-			hasExtension(path, extensions)
-		}
-	}
-}
-
-// BenchmarkRegexMatching measures pattern matching performance.
-func BenchmarkRegexMatching(b *testing.B) {
-	fast, err := loadRegexes(regexFS, "direct_matches.regex", "fast_patterns.regex")
-	if err != nil {
-		b.Fatal(err)
-	}
-	slow, err := loadRegexes(regexFS, "direct_matches.regex", "strict_patterns.regex")
-	if err != nil {
-		b.Fatal(err)
-	}
-	exclude, err := loadRegexes(regexFS, "exclude_patterns.regex")
-	if err != nil {
-		b.Fatal(err)
-	}
-
-	testLines := []string{
-		`PASSWORD="mysecretpassword123"`,
-		`AWS_SECRET_ACCESS_KEY=abcdefghijklmnop`,
-		`api_key: "sk_live_1234567890abcdef"`,
-		`const password = "test123"`,
-		`password: str = None`,
-		`ADMIN_PASSWORD: "${ADMIN_PASSWORD}"`,
-	}
-
-	b.Run("fast_patterns", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			for _, line := range testLines {
-				fast.MatchString(line)
-			}
-		}
-	})
-
-	b.Run("strict_patterns", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			for _, line := range testLines {
-				slow.MatchString(line)
-			}
-		}
-	})
-
-	b.Run("exclude_patterns", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			for _, line := range testLines {
-				exclude.MatchString(line)
-			}
-		}
-	})
-
-	b.Run("full_pipeline", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			for _, line := range testLines {
-				if fast.MatchString(line) {
-					if match := slow.FindString(line); match != "" && !exclude.MatchString(line) {
-						_ = match
-					}
-				}
-			}
-		}
-	})
-}
-
 // BenchmarkWriteResults measures output writing performance.
 func BenchmarkWriteResults(b *testing.B) {
 	matches := make([]string, 1000)
@@ -155,7 +44,7 @@ api_key = "sk_test_abc123"
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		outputFile := filepath.Join(b.TempDir(), "bench_output.txt")
-		err := runFasthog(tmpDir, []string{".py"}, nil, PatternFiles{}, outputFile)
+		_, err := runFasthog(runOptions{Directory: tmpDir, Extensions: []string{".py"}, OutputPath: outputFile})
 		if err != nil {
 			b.Fatal(err)
 		}
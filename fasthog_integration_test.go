@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/bordenet/secrets-in-source/pkg/fasthog"
 )
 
 // TestIntegrationScanDirectory performs end-to-end testing of directory scanning.
@@ -19,7 +21,7 @@ func TestIntegrationScanDirectory(t *testing.T) {
 		testDir := "test"
 		outputFile := filepath.Join(t.TempDir(), "results.json")
 
-		err := runFasthogJSON(testDir, defaultExtensions, nil, PatternFiles{}, outputFile)
+		_, err := runFasthogJSON(runOptions{Directory: testDir, Extensions: fasthog.DefaultExtensions, OutputPath: outputFile})
 		if err != nil {
 			t.Fatalf("runFasthogJSON failed: %v", err)
 		}
@@ -51,7 +53,7 @@ func TestIntegrationScanDirectory(t *testing.T) {
 		outputFile := filepath.Join(t.TempDir(), "results.json")
 		extensions := []string{".txt"}
 
-		err := runFasthogJSON(testDir, extensions, nil, PatternFiles{}, outputFile)
+		_, err := runFasthogJSON(runOptions{Directory: testDir, Extensions: extensions, OutputPath: outputFile})
 		if err != nil {
 			t.Fatalf("runFasthogJSON failed: %v", err)
 		}
@@ -72,7 +74,7 @@ func TestIntegrationScanDirectory(t *testing.T) {
 		emptyDir := t.TempDir()
 		outputFile := filepath.Join(t.TempDir(), "results.json")
 
-		err := runFasthogJSON(emptyDir, defaultExtensions, nil, PatternFiles{}, outputFile)
+		_, err := runFasthogJSON(runOptions{Directory: emptyDir, Extensions: fasthog.DefaultExtensions, OutputPath: outputFile})
 		if err != nil {
 			t.Fatalf("runFasthogJSON failed: %v", err)
 		}
@@ -101,7 +103,7 @@ func TestIntegrationScanDirectory(t *testing.T) {
 		}
 
 		outputFile := filepath.Join(t.TempDir(), "results.json")
-		err = runFasthogJSON(tmpDir, []string{".py"}, nil, PatternFiles{}, outputFile)
+		_, err = runFasthogJSON(runOptions{Directory: tmpDir, Extensions: []string{".py"}, OutputPath: outputFile})
 		if err != nil {
 			t.Fatalf("runFasthogJSON failed: %v", err)
 		}
@@ -121,7 +123,7 @@ func TestIntegrationScanDirectory(t *testing.T) {
 	})
 
 	t.Run("nonexistent directory", func(t *testing.T) {
-		err := runFasthogJSON("/nonexistent/directory", defaultExtensions, nil, PatternFiles{}, "")
+		_, err := runFasthogJSON(runOptions{Directory: "/nonexistent/directory", Extensions: fasthog.DefaultExtensions})
 		if err == nil {
 			t.Error("expected error for nonexistent directory")
 		}
@@ -150,7 +152,7 @@ func TestIntegrationExcludeDirectories(t *testing.T) {
 	}
 
 	outputFile := filepath.Join(t.TempDir(), "results.json")
-	err = runFasthogJSON(tmpDir, defaultExtensions, nil, PatternFiles{}, outputFile)
+	_, err = runFasthogJSON(runOptions{Directory: tmpDir, Extensions: fasthog.DefaultExtensions, OutputPath: outputFile})
 	if err != nil {
 		t.Fatalf("runFasthogJSON failed: %v", err)
 	}
@@ -193,7 +195,7 @@ func TestIntegrationTopFilesReport(t *testing.T) {
 	}
 
 	outputFile := filepath.Join(t.TempDir(), "results.json")
-	err := runFasthogJSON(tmpDir, []string{".py"}, nil, PatternFiles{}, outputFile)
+	_, err := runFasthogJSON(runOptions{Directory: tmpDir, Extensions: []string{".py"}, OutputPath: outputFile})
 	if err != nil {
 		t.Fatalf("runFasthogJSON failed: %v", err)
 	}
@@ -229,8 +231,42 @@ func TestIntegrationNoOutputFile(t *testing.T) {
 	}
 
 	// Run without output file (empty string) - JSON goes to stdout
-	err = runFasthogJSON(tmpDir, []string{".py"}, nil, PatternFiles{}, "")
+	_, err = runFasthogJSON(runOptions{Directory: tmpDir, Extensions: []string{".py"}})
 	if err != nil {
 		t.Fatalf("runFasthogJSON failed: %v", err)
 	}
 }
+
+// TestIntegrationRedaction verifies that JSON output masks secrets unless
+// redaction is disabled.
+func TestIntegrationRedaction(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tmpDir := t.TempDir()
+	secret := "mysecretpassword123"
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.py"), []byte(`PASSWORD="`+secret+`"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, noRedact := range []bool{false, true} {
+		t.Run(fmt.Sprintf("noRedact=%v", noRedact), func(t *testing.T) {
+			outputFile := filepath.Join(t.TempDir(), "results.json")
+			_, err := runFasthogJSON(runOptions{Directory: tmpDir, Extensions: []string{".py"}, OutputPath: outputFile, NoRedact: noRedact})
+			if err != nil {
+				t.Fatalf("runFasthogJSON failed: %v", err)
+			}
+			data, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(data), secret); got != noRedact {
+				t.Errorf("output contains the secret: %v, want %v\n%s", got, noRedact, data)
+			}
+			if !strings.Contains(string(data), `"secret_hash"`) {
+				t.Error("output is missing the secret hash")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bordenet/secrets-in-source/pkg/fasthog"
+)
+
+// preCommitHook is the script written by the install-hook subcommand. It
+// relies on fasthog being on PATH so the hook survives reinstalls and works
+// for every clone of the repository.
+const preCommitHook = `#!/bin/sh
+# Installed by "fasthog install-hook": scan staged changes for secrets.
+# Bypass once with "git commit --no-verify".
+exec fasthog --staged
+`
+
+// installHook writes the pre-commit hook of the git repository containing
+// dir and returns its path. An existing hook other than fasthog's own is only
+// replaced when force is set.
+func installHook(dir string, force bool) (string, error) {
+	if err := validateDirectory(dir); err != nil {
+		return "", err
+	}
+	if err := fasthog.ValidateGitRepo(dir, "install-hook"); err != nil {
+		return "", err
+	}
+
+	// --git-path resolves linked worktrees and core.hooksPath.
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks/pre-commit").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return "", fmt.Errorf("cannot read existing hook: %w", err)
+	case string(existing) != preCommitHook && !force:
+		return "", fmt.Errorf("pre-commit hook already exists: %s (use --force to replace it)", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("cannot create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(preCommitHook), 0o755); err != nil {
+		return "", fmt.Errorf("cannot write pre-commit hook: %w", err)
+	}
+	// WriteFile keeps the mode of a file it replaces.
+	if err := os.Chmod(path, 0o755); err != nil {
+		return "", fmt.Errorf("cannot make pre-commit hook executable: %w", err)
+	}
+	return path, nil
+}
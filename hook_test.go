@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newGitRepo initializes an empty git repository in a temporary directory.
+func newGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "--quiet", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	return dir
+}
+
+func TestInstallHook(t *testing.T) {
+	dir := newGitRepo(t)
+	hookPath := filepath.Join(dir, ".git", "hooks", "pre-commit")
+
+	path, err := installHook(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != hookPath {
+		t.Errorf("expected hook at %s, got %s", hookPath, path)
+	}
+	info, err := os.Stat(hookPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("hook is not executable: %v", info.Mode())
+	}
+	data, err := os.ReadFile(hookPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "fasthog --staged") {
+		t.Errorf("hook does not run fasthog --staged:\n%s", data)
+	}
+
+	t.Run("reinstall is idempotent", func(t *testing.T) {
+		if _, err := installHook(dir, false); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("existing hook is kept without force", func(t *testing.T) {
+		custom := "#!/bin/sh\nmake lint\n"
+		if err := os.WriteFile(hookPath, []byte(custom), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := installHook(dir, false)
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("expected already exists error, got %v", err)
+		}
+		if data, _ := os.ReadFile(hookPath); string(data) != custom {
+			t.Errorf("existing hook was modified:\n%s", data)
+		}
+
+		if _, err := installHook(dir, true); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(hookPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(hookPath); string(data) != preCommitHook || info.Mode().Perm()&0o111 == 0 {
+			t.Errorf("forced install did not replace the hook: %v\n%s", info.Mode(), data)
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		_, err := installHook(t.TempDir(), false)
+		if err == nil || !strings.Contains(err.Error(), "not a git repository") {
+			t.Errorf("expected not a git repository error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,313 @@
+package fasthog
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const (
+	// defaultArchiveDepth is how many levels of archives within archives
+	// are opened; the outermost archive is level 1.
+	defaultArchiveDepth = 3
+
+	// defaultArchiveMaxBytes bounds the bytes read from one archive on disk
+	// and everything nested in it, to defuse zip bombs.
+	defaultArchiveMaxBytes = 256 << 20
+
+	// archiveSeparator joins an archive's path and the path of a member
+	// within it, as in dist/app.jar!/config/application.properties.
+	archiveSeparator = "!/"
+)
+
+// Archive kinds, as returned by archiveKind.
+const (
+	archiveZip   = "zip"
+	archiveTar   = "tar"
+	archiveTarGz = "tar.gz"
+)
+
+// archiveExtensions maps the extensions of supported archives to their kind.
+var archiveExtensions = []struct {
+	ext  string
+	kind string
+}{
+	{".zip", archiveZip}, {".jar", archiveZip}, {".war", archiveZip},
+	{".ear", archiveZip}, {".aar", archiveZip}, {".whl", archiveZip},
+	{".nupkg", archiveZip},
+	{".tar", archiveTar},
+	{".tar.gz", archiveTarGz}, {".tgz", archiveTarGz},
+}
+
+// errArchiveTooLarge ends the scan of an archive that exceeds the byte limit.
+var errArchiveTooLarge = errors.New("archive exceeds --archive-max-bytes; the rest was not scanned")
+
+// ArchiveOptions limits how deep and how much of an archive is scanned.
+type ArchiveOptions struct {
+	// MaxDepth is the deepest level of nested archive opened. Zero disables
+	// archive scanning.
+	MaxDepth int
+	// MaxBytes bounds the bytes read from one archive, including its nested
+	// archives and members.
+	MaxBytes int64
+}
+
+// DefaultArchiveOptions returns the default archive limits.
+func DefaultArchiveOptions() ArchiveOptions {
+	return ArchiveOptions{MaxDepth: defaultArchiveDepth, MaxBytes: defaultArchiveMaxBytes}
+}
+
+// includes reports whether p is an archive to be scanned, whatever the
+// scanned extensions.
+func (ao ArchiveOptions) includes(p string) bool {
+	return ao.MaxDepth > 0 && archiveKind(p) != ""
+}
+
+// archiveKind returns the kind of archive named by p, or "" if p is not an
+// archive.
+func archiveKind(p string) string {
+	lower := strings.ToLower(p)
+	for _, ae := range archiveExtensions {
+		if strings.HasSuffix(lower, ae.ext) {
+			return ae.kind
+		}
+	}
+	return ""
+}
+
+// limitedReader fails with errArchiveTooLarge once the bytes read through
+// every limitedReader sharing remaining exceed the archive's limit.
+type limitedReader struct {
+	r         io.Reader
+	remaining *int64
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if *lr.remaining <= 0 {
+		return 0, errArchiveTooLarge
+	}
+	if int64(len(p)) > *lr.remaining {
+		p = p[:*lr.remaining]
+	}
+	n, err := lr.r.Read(p)
+	*lr.remaining -= int64(n)
+	return n, err
+}
+
+// archiveScanner scans the members of one archive on disk and of the
+// archives nested in it. It is not safe for concurrent use.
+type archiveScanner struct {
+	opts        scanOptions
+	excludeDirs []string
+	remaining   int64
+	res         Result
+	// tooLarge is set once the byte limit is reached, ending the scan.
+	tooLarge bool
+}
+
+// scanArchive scans the members of the archive at path, read from r, as if
+// they were files named path!/member. Members are selected like the files
+// of a directory scan; archives among them are opened in turn, up to
+// opts.Archives.MaxDepth levels deep.
+func scanArchive(opts scanOptions, path, kind string, r io.Reader) Result {
+	as := &archiveScanner{
+		opts:        opts,
+		excludeDirs: mergeExcludeDirs(opts.ExcludeDirs),
+		remaining:   opts.Archives.MaxBytes,
+		res:         Result{MatchFiles: make(map[string]int)},
+	}
+	as.scan(path, kind, r, 1)
+	if as.tooLarge {
+		as.res.Errors = append(as.res.Errors, ScanError{File: path, Op: "read", Err: errArchiveTooLarge})
+	}
+	return as.res
+}
+
+// scan scans the members of the archive at the virtual path, read from r,
+// at the given nesting depth. Every uncompressed byte counts towards the
+// limit once: a nested archive is read from a member whose bytes have
+// already been counted.
+func (as *archiveScanner) scan(path, kind string, r io.Reader, depth int) {
+	var err error
+	switch kind {
+	case archiveZip:
+		err = as.scanZip(path, r, depth)
+	case archiveTarGz:
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(r)
+		if err == nil {
+			err = as.scanTar(path, as.limit(gz), depth)
+		}
+	default:
+		if depth == 1 {
+			r = as.limit(r)
+		}
+		err = as.scanTar(path, r, depth)
+	}
+	as.recordError(path, "open", err)
+}
+
+// limit wraps r so that the bytes read from it count towards the archive's
+// byte limit.
+func (as *archiveScanner) limit(r io.Reader) io.Reader {
+	return &limitedReader{r: r, remaining: &as.remaining}
+}
+
+// recordError records err, unless it is nil or the byte limit, which is
+// reported once for the whole archive.
+func (as *archiveScanner) recordError(path, op string, err error) {
+	switch {
+	case err == nil:
+	case errors.Is(err, errArchiveTooLarge):
+		as.tooLarge = true
+	default:
+		as.res.Errors = append(as.res.Errors, ScanError{File: path, Op: op, Err: err})
+	}
+}
+
+// scanZip scans the members of a zip archive. An archive on disk is read in
+// place; a nested one is read into memory, within the byte limit.
+func (as *archiveScanner) scanZip(path string, r io.Reader, depth int) error {
+	var (
+		ra   io.ReaderAt
+		size int64
+	)
+	if f, ok := r.(interface {
+		io.ReaderAt
+		Stat() (fs.FileInfo, error)
+	}); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		ra, size = f, info.Size()
+	} else {
+		if depth == 1 {
+			r = as.limit(r)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		ra, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if as.tooLarge {
+			return nil
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			as.recordError(memberPath(path, f.Name), "open", err)
+			continue
+		}
+		as.member(path, f.Name, as.limit(rc), depth)
+		_ = rc.Close() // Read-only; nothing to flush
+	}
+	return nil
+}
+
+// scanTar scans the regular files of a tar stream.
+func (as *archiveScanner) scanTar(path string, r io.Reader, depth int) error {
+	tr := tar.NewReader(r)
+	for !as.tooLarge {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		as.member(path, hdr.Name, tr, depth)
+	}
+	return nil
+}
+
+// memberPath returns the virtual path of the member name of the archive at
+// archivePath.
+func memberPath(archivePath, name string) string {
+	return archivePath + archiveSeparator + cleanMemberName(name)
+}
+
+// cleanMemberName returns the slash-separated name of an archive member
+// relative to the archive root, without "./" or leading slashes.
+func cleanMemberName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// member scans the member name of the archive at archivePath, read from r
+// at the given depth. Reads from r count towards the byte limit.
+func (as *archiveScanner) member(archivePath, name string, r io.Reader, depth int) {
+	name = cleanMemberName(name)
+	vpath := archivePath + archiveSeparator + name
+	if inExcludedDir(name, as.excludeDirs) {
+		return
+	}
+
+	if kind := archiveKind(name); kind != "" {
+		if depth >= as.opts.Archives.MaxDepth {
+			as.res.Warnings = append(as.res.Warnings, ScanWarning{
+				File:    vpath,
+				Message: fmt.Sprintf("nested archive not scanned: deeper than --archive-depth %d", as.opts.Archives.MaxDepth),
+			})
+			return
+		}
+		as.scan(vpath, kind, r, depth+1)
+		return
+	}
+
+	opts := as.opts
+	include := includePath(name, opts.Extensions, opts.Filenames, nil)
+	sensitive := matchSensitiveFile(name) != nil
+	sniff := !include && opts.SniffExtensionless && isExtensionless(name)
+	if !include && !sensitive && !sniff {
+		return
+	}
+
+	// A sensitive file may be read twice: once for its content and once to
+	// confirm its rule.
+	var data []byte
+	if sensitive {
+		var err error
+		if data, err = io.ReadAll(r); err != nil {
+			as.recordError(vpath, "read", err)
+			return
+		}
+		r = bytes.NewReader(data)
+	}
+	if sniff {
+		br := bufio.NewReaderSize(r, sniffLength)
+		head, _ := br.Peek(sniffLength)
+		include, r = looksLikeText(head), br
+	}
+
+	if include {
+		as.res.Filenames = append(as.res.Filenames, vpath)
+		fileRes := scanContent(opts, vpath, r)
+		as.recordError(vpath, "read", fileRes.Err)
+		addFileScan(&as.res, vpath, fileRes)
+	}
+	if sensitive {
+		scanSensitiveFiles(opts, &as.res, []string{vpath}, func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package fasthog
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// zipBytes returns a zip archive of files, in name order.
+func zipBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// tarGzBytes returns a gzip-compressed tar archive of files, in name order.
+func tarGzBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name]))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// scanArchiveFixture writes data to dist/<name> in a temporary directory and
+// scans it with the given archive options.
+func scanArchiveFixture(t *testing.T, name string, data []byte, archives ArchiveOptions) Result {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "dist"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dist", name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return scanDirectory(testScanOptions(t, scanOptions{
+		Directory:  dir,
+		Extensions: []string{".properties", ".env", ".py"},
+		Archives:   archives,
+	}))
+}
+
+func findingPaths(res Result) []string {
+	var paths []string
+	for _, m := range res.Matches {
+		paths = append(paths, m.File+" "+m.RuleID)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestScanArchiveNested(t *testing.T) {
+	inner := tarGzBytes(t, map[string]string{
+		"./app/.env":   "PASSWORD=\"mysecretpassword123\"\n",
+		"app/README":   "nothing to see\n",
+		"keys/id_rsa":  "-\n",
+		"app/logo.png": "\x89PNG\x00\x00PASSWORD=\"mysecretpassword123\"",
+	})
+	jar := zipBytes(t, map[string]string{
+		"config/application.properties": "db.password=\"anothersecretvalue9\"\n",
+		"lib/inner.tgz":                 string(inner),
+		"node_modules/x/config.py":      "PASSWORD=\"mysecretpassword123\"\n",
+		"META-INF/":                     "",
+	})
+
+	res := scanArchiveFixture(t, "app.jar", jar, DefaultArchiveOptions())
+	var got []string
+	for _, m := range res.Matches {
+		got = append(got, m.File)
+	}
+	slices.Sort(got)
+	want := []string{
+		"dist/app.jar!/config/application.properties",
+		"dist/app.jar!/lib/inner.tgz!/app/.env",
+		"dist/app.jar!/lib/inner.tgz!/keys/id_rsa",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got findings in %q, want %q", got, want)
+	}
+	if len(res.Errors) != 0 || len(res.Warnings) != 0 {
+		t.Errorf("unexpected errors %v or warnings %v", res.Errors, res.Warnings)
+	}
+	for _, name := range want[:2] {
+		if !slices.Contains(res.Filenames, name) || res.MatchFiles[name] != 1 {
+			t.Errorf("expected %s among the scanned files with one match, got %v / %v", name, res.Filenames, res.MatchFiles)
+		}
+	}
+}
+
+func TestScanArchiveDepthLimit(t *testing.T) {
+	inner := zipBytes(t, map[string]string{"secret.env": "PASSWORD=\"mysecretpassword123\"\n"})
+	outer := zipBytes(t, map[string]string{"inner.zip": string(inner)})
+
+	res := scanArchiveFixture(t, "outer.zip", outer, ArchiveOptions{MaxDepth: 1, MaxBytes: defaultArchiveMaxBytes})
+	if len(res.Matches) != 0 {
+		t.Errorf("expected the nested archive to be left unscanned, got %+v", res.Matches)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].File != "dist/outer.zip!/inner.zip" {
+		t.Errorf("expected a depth warning for the nested archive, got %+v", res.Warnings)
+	}
+
+	res = scanArchiveFixture(t, "outer.zip", outer, ArchiveOptions{MaxDepth: 0, MaxBytes: defaultArchiveMaxBytes})
+	if len(res.Filenames) != 0 || len(res.Matches) != 0 {
+		t.Errorf("expected archives to be ignored at depth 0, got %v", res.Filenames)
+	}
+}
+
+func TestScanArchiveSizeLimit(t *testing.T) {
+	// A highly compressible member expands far beyond the limit.
+	bomb := tarGzBytes(t, map[string]string{
+		"a.env": strings.Repeat("x", 1<<20) + "\nPASSWORD=\"mysecretpassword123\"\n",
+		"b.env": "PASSWORD=\"mysecretpassword123\"\n",
+	})
+
+	res := scanArchiveFixture(t, "bomb.tar.gz", bomb, ArchiveOptions{MaxDepth: 1, MaxBytes: 64 << 10})
+	if len(res.Errors) != 1 || res.Errors[0].File != "dist/bomb.tar.gz" || !errors.Is(res.Errors[0].Err, errArchiveTooLarge) {
+		t.Fatalf("expected one size limit error for the archive, got %+v", res.Errors)
+	}
+	if len(res.Matches) != 0 {
+		t.Errorf("expected nothing past the limit to be scanned, got %v", findingPaths(res))
+	}
+}
+
+func TestScanArchiveCorrupt(t *testing.T) {
+	res := scanArchiveFixture(t, "broken.zip", []byte("not a zip"), DefaultArchiveOptions())
+	if len(res.Errors) != 1 || res.Errors[0].File != "dist/broken.zip" {
+		t.Errorf("expected an open error for the archive, got %+v", res.Errors)
+	}
+}
+
+func TestArchiveKind(t *testing.T) {
+	tests := map[string]string{
+		"dist/app.jar":       archiveZip,
+		"wheel.WHL":          archiveZip,
+		"backup.tar":         archiveTar,
+		"release.tar.gz":     archiveTarGz,
+		"pkg.tgz":            archiveTarGz,
+		"notes.txt":          "",
+		"app.jar.properties": "",
+	}
+	for p, want := range tests {
+		if got := archiveKind(p); got != want {
+			t.Errorf("archiveKind(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
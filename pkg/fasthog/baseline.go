@@ -0,0 +1,122 @@
+package fasthog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// baselineVersion is written to, and required of, baseline files.
+const baselineVersion = 1
+
+// BaselineFile is the on-disk format of a --baseline file. Entries keep the
+// file and rule of each fingerprint so that the baseline can be reviewed.
+type BaselineFile struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry identifies one accepted finding.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	File        string `json:"file"`
+	RuleID      string `json:"rule_id"`
+}
+
+// Baseline is the set of fingerprints of previously reviewed findings, which
+// are suppressed from the results.
+type Baseline map[string]struct{}
+
+// contains reports whether the finding with the given fingerprint is accepted.
+func (b Baseline) contains(fingerprint string) bool {
+	_, ok := b[fingerprint]
+	return ok
+}
+
+// fingerprint identifies a finding by its path, rule and normalized secret
+// value rather than its position or the text matched around the value, so
+// that unrelated edits to a file do not invalidate baselined findings.
+func fingerprint(m Match) string {
+	h := sha256.New()
+	h.Write([]byte(m.File))
+	h.Write([]byte{0})
+	h.Write([]byte(m.RuleID))
+	h.Write([]byte{0})
+	h.Write([]byte(normalizeSecret(matchSecret(m))))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeSecret removes whitespace and unifies quote characters, so that
+// reformatting an assignment or re-wrapping a key block keeps its fingerprint.
+func normalizeSecret(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r):
+			return -1
+		case r == '\'' || r == '`':
+			return '"'
+		default:
+			return r
+		}
+	}, s)
+}
+
+// LoadBaseline reads a baseline file written by WriteBaseline.
+func LoadBaseline(path string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read baseline file %s: %w", path, err)
+	}
+	var file BaselineFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid baseline file %s: %w", path, err)
+	}
+	if file.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s (expected %d)", file.Version, path, baselineVersion)
+	}
+
+	b := make(Baseline, len(file.Entries))
+	for _, e := range file.Entries {
+		b[e.Fingerprint] = struct{}{}
+	}
+	return b, nil
+}
+
+// WriteBaseline records the fingerprints of matches as the accepted findings
+// in path. Entries are sorted and deduplicated so that the file diffs cleanly
+// between updates.
+func WriteBaseline(path string, matches []Match) error {
+	seen := make(map[string]struct{}, len(matches))
+	file := BaselineFile{Version: baselineVersion, Entries: []BaselineEntry{}}
+	for _, m := range matches {
+		if _, ok := seen[m.Fingerprint]; ok {
+			continue
+		}
+		seen[m.Fingerprint] = struct{}{}
+		file.Entries = append(file.Entries, BaselineEntry{Fingerprint: m.Fingerprint, File: m.File, RuleID: m.RuleID})
+	}
+	sort.Slice(file.Entries, func(i, j int) bool {
+		a, b := file.Entries[i], file.Entries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package fasthog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	base := Match{File: "app/config.py", Line: 3, Column: 1, RuleID: "password-assignment", MatchText: `PASSWORD="hunter2hunter2"`}
+
+	moved := base
+	moved.Line, moved.Column = 40, 9
+	moved.MatchText = `PASSWORD = 'hunter2hunter2'`
+	if fingerprint(moved) != fingerprint(base) {
+		t.Error("fingerprint should ignore position, whitespace and quote style")
+	}
+
+	trailing := base
+	trailing.MatchText = `PASSWORD="hunter2hunter2";`
+	if fingerprint(trailing) != fingerprint(base) {
+		t.Error("fingerprint should ignore text matched after the secret")
+	}
+
+	for name, m := range map[string]Match{
+		"other file":   {File: "app/other.py", RuleID: base.RuleID, MatchText: base.MatchText},
+		"other rule":   {File: base.File, RuleID: "generic-secret", MatchText: base.MatchText},
+		"other secret": {File: base.File, RuleID: base.RuleID, MatchText: `PASSWORD="hunter3hunter3"`},
+	} {
+		if fingerprint(m) == fingerprint(base) {
+			t.Errorf("%s: fingerprint should differ", name)
+		}
+	}
+}
+
+func TestBaselineSuppressesReviewedFindings(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "config.py"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("PASSWORD=\"mysecretpassword123\"\n")
+
+	opts := testScanOptions(t, scanOptions{
+		Directory:  dir,
+		Extensions: []string{".py"},
+	})
+
+	first := scanDirectory(opts)
+	if len(first.Matches) != 1 || first.Matches[0].Fingerprint == "" {
+		t.Fatalf("expected 1 fingerprinted match, got %+v", first.Matches)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := WriteBaseline(path, append(first.Matches, first.Matches...)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), `"fingerprint"`); n != 1 {
+		t.Errorf("expected duplicates to be written once, got %d entries:\n%s", n, data)
+	}
+
+	opts.Baseline, err = LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving the accepted secret and adding a new one reports only the new one.
+	write("import os\n\nPASSWORD = \"mysecretpassword123\"\nTOKEN=\"anothersecretvalue9\"\n")
+	res := scanDirectory(opts)
+	if len(res.Matches) != 1 || res.Matches[0].Line != 4 {
+		t.Errorf("expected only the new finding on line 4, got %+v", res.Matches)
+	}
+	if res.Suppressed != 1 {
+		t.Errorf("expected 1 suppressed finding, got %d", res.Suppressed)
+	}
+}
+
+func TestBaselineIgnoresTrailingContext(t *testing.T) {
+	// default-argument-secret matches the character after the quoted value,
+	// which is not part of the secret.
+	dir := t.TempDir()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "settings.py"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("db = get(PASSWORD(default='Xk9mQ2vL8pR4wZ7n'))\n")
+
+	opts := testScanOptions(t, scanOptions{
+		Directory:  dir,
+		Extensions: []string{".py"},
+	})
+
+	first := scanDirectory(opts)
+	if len(first.Matches) != 1 || !strings.HasSuffix(first.Matches[0].MatchText, "')") {
+		t.Fatalf("expected 1 match with trailing context, got %+v", first.Matches)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := WriteBaseline(path, first.Matches); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	opts.Baseline, err = LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write("db = get(PASSWORD(default='Xk9mQ2vL8pR4wZ7n', cast=str))\n")
+	res := scanDirectory(opts)
+	if len(res.Matches) != 0 || res.Suppressed != 1 {
+		t.Errorf("expected the finding to stay suppressed, got %d suppressed and %+v", res.Suppressed, res.Matches)
+	}
+}
+
+func TestLoadBaselineErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"missing", "", "unable to read baseline"},
+		{"malformed", "{", "invalid baseline"},
+		{"wrong version", `{"version": 99, "findings": []}`, "unsupported baseline version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := LoadBaseline(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package fasthog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// binarySniffLength is how many leading bytes of a file are inspected to
+// decide whether it is binary.
+const binarySniffLength = 8 << 10
+
+// binaryInvalidRatio is the share of bytes that are not valid UTF-8 above
+// which a file without NUL bytes is still considered binary. Text in legacy
+// single-byte encodings stays well below it.
+const binaryInvalidRatio = 0.3
+
+// minBinaryStringLength is the shortest run of printable characters taken
+// from a binary file with --binary-strings. Shorter runs are mostly noise and
+// too short to hold a secret.
+const minBinaryStringLength = 8
+
+// maxBinaryStringLength bounds how much of a run of printable characters is
+// buffered before it is passed on as part of an unfinished line.
+const maxBinaryStringLength = 64 << 10
+
+// isBinary reports whether data, the start of a file, is binary: it holds a
+// NUL byte, or too many bytes that are not valid UTF-8. A multi-byte
+// character cut off at the end of data is not counted.
+func isBinary(data []byte) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	invalid := 0
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			if len(data)-i < utf8.UTFMax && !utf8.FullRune(data[i:]) {
+				break
+			}
+			invalid++
+		}
+		i += size
+	}
+	return float64(invalid) > binaryInvalidRatio*float64(len(data))
+}
+
+// sniffBinary wraps r in a buffered reader and reports whether its first
+// binarySniffLength bytes are binary. The returned reader still yields every
+// byte of r.
+func sniffBinary(r io.Reader) (*bufio.Reader, bool, error) {
+	br := bufio.NewReaderSize(r, binarySniffLength)
+	head, err := br.Peek(binarySniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return br, false, err
+	}
+	return br, isBinary(head), nil
+}
+
+// printableStrings reads the runs of at least minBinaryStringLength printable
+// ASCII characters in a binary stream, one per line, as strings(1) does.
+type printableStrings struct {
+	r *bufio.Reader
+	// run is the current run of printable characters; partial is set once
+	// part of it has been passed on because it grew too long.
+	run     []byte
+	partial bool
+	out     []byte
+}
+
+// newPrintableStrings returns a reader of the printable strings in r.
+func newPrintableStrings(r *bufio.Reader) *printableStrings {
+	return &printableStrings{r: r}
+}
+
+// isPrintable reports whether b is printable ASCII or a tab.
+func isPrintable(b byte) bool {
+	return b == '\t' || (b >= 0x20 && b < 0x7f)
+}
+
+func (ps *printableStrings) Read(p []byte) (int, error) {
+	for len(ps.out) == 0 {
+		b, err := ps.r.ReadByte()
+		if err != nil {
+			if !ps.endRun() {
+				return 0, err
+			}
+			break
+		}
+		if !isPrintable(b) {
+			ps.endRun()
+			continue
+		}
+		ps.run = append(ps.run, b)
+		if len(ps.run) >= maxBinaryStringLength {
+			ps.out = append(ps.out[:0], ps.run...)
+			ps.run, ps.partial = ps.run[:0], true
+		}
+	}
+	n := copy(p, ps.out)
+	ps.out = ps.out[n:]
+	return n, nil
+}
+
+// endRun ends the current run, queueing it as a line if it is long enough,
+// and reports whether anything was queued.
+func (ps *printableStrings) endRun() bool {
+	queued := false
+	if ps.partial || len(ps.run) >= minBinaryStringLength {
+		ps.out = append(append(ps.out[:0], ps.run...), '\n')
+		queued = true
+	}
+	ps.run, ps.partial = ps.run[:0], false
+	return queued
+}
@@ -0,0 +1,129 @@
+package fasthog
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encodings recorded in Match.Encoded for findings in decoded values.
+const (
+	encodingBase64 = "base64"
+	encodingHex    = "hex"
+)
+
+// minEncodedLength is the shortest base64 or hex value decoded; shorter ones
+// cannot hold a secret worth reporting.
+const minEncodedLength = 16
+
+// encodedCandidate matches runs of base64, base64url or hex characters.
+var encodedCandidate = regexp.MustCompile(`[A-Za-z0-9+/_-]{16,}={0,2}`)
+
+// encodedValue is a value on a line that decodes to printable text.
+type encodedValue struct {
+	// Loc is the byte range of the encoded value in the line.
+	Loc      []int
+	Encoding string
+	Text     string
+}
+
+// findEncoded returns the base64 and hex values in line that decode to
+// printable text. Hex is tried first for values made only of hex digits.
+func findEncoded(line string) []encodedValue {
+	var values []encodedValue
+	for _, loc := range encodedCandidate.FindAllStringIndex(line, -1) {
+		value := line[loc[0]:loc[1]]
+		if decoded, ok := decodeHex(value); ok {
+			values = append(values, encodedValue{Loc: loc, Encoding: encodingHex, Text: decoded})
+		} else if decoded, ok := decodeBase64(value); ok {
+			values = append(values, encodedValue{Loc: loc, Encoding: encodingBase64, Text: decoded})
+		}
+	}
+	return values
+}
+
+// decodeHex decodes an even-length run of hex digits to printable text.
+func decodeHex(value string) (string, bool) {
+	if len(value)%2 != 0 || !hexString.MatchString(value) {
+		return "", false
+	}
+	data, err := hex.DecodeString(value)
+	if err != nil || !isPrintableText(data) {
+		return "", false
+	}
+	return string(data), true
+}
+
+// decodeBase64 decodes a padded base64 or base64url value to printable
+// text. Unpadded values are not decoded: almost any identifier of the right
+// alphabet would be a candidate.
+func decodeBase64(value string) (string, bool) {
+	if len(value)%4 != 0 {
+		return "", false
+	}
+	enc := base64.StdEncoding
+	if strings.ContainsAny(value, "-_") {
+		enc = base64.URLEncoding
+	}
+	data, err := enc.DecodeString(value)
+	if err != nil || !isPrintableText(data) {
+		return "", false
+	}
+	return string(data), true
+}
+
+// isPrintableText reports whether data is UTF-8 made only of printable
+// characters and whitespace, at least minEncodedLength/2 bytes long.
+func isPrintableText(data []byte) bool {
+	if len(data) < minEncodedLength/2 || !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// decodedMatch is a strict pattern match in a decoded value.
+type decodedMatch struct {
+	value encodedValue
+	rule  *Rule
+	// text is the matched decoded text. For a value scanned in place of the
+	// encoded one, it is the part of the match within the value.
+	text string
+}
+
+// scanEncoded decodes the encoded values in line and rescans them with the
+// fast, strict and exclude stages. A single-line value is scanned in place of
+// the encoded one, so that "password: <base64>" matches as the decoded
+// assignment; each line of a multi-line value is scanned on its own. Only
+// matches that include decoded text are returned.
+func scanEncoded(opts scanOptions, line string) []decodedMatch {
+	var found []decodedMatch
+	for _, ev := range findEncoded(line) {
+		decodedLines := strings.Split(strings.TrimRight(ev.Text, "\r\n"), "\n")
+		for _, dl := range decodedLines {
+			dl = strings.TrimSuffix(dl, "\r")
+			text, from, to := dl, 0, len(dl)
+			if len(decodedLines) == 1 {
+				text = line[:ev.Loc[0]] + dl + line[ev.Loc[1]:]
+				from, to = ev.Loc[0], ev.Loc[0]+len(dl)
+			}
+			if !opts.FastPatterns.MatchString(text) || opts.ExcludePatterns.MatchString(text) {
+				continue
+			}
+			for _, rm := range opts.SlowPatterns.findAllStringIndex(text) {
+				if rm.Loc[0] < to && from < rm.Loc[1] {
+					matched := text[max(rm.Loc[0], from):min(rm.Loc[1], to)]
+					found = append(found, decodedMatch{value: ev, rule: rm.Rule, text: matched})
+				}
+			}
+		}
+	}
+	return found
+}
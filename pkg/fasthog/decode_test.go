@@ -0,0 +1,100 @@
+package fasthog
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestFindEncoded(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString([]byte("supersecretpassword1"))
+	urlSafe := base64.URLEncoding.EncodeToString([]byte("token=abc>>>???~~~"))
+	hexed := hex.EncodeToString([]byte("PASSWORD=hunter22"))
+	binary := base64.StdEncoding.EncodeToString([]byte{0x30, 0x82, 0x01, 0x0a, 0x02, 0x82, 0x01, 0x01, 0x00, 0xc4, 0x9f, 0x11})
+
+	tests := []struct {
+		name     string
+		line     string
+		encoding string
+		text     string
+	}{
+		{"base64", "password: " + b64, encodingBase64, "supersecretpassword1"},
+		{"base64url", "v=" + urlSafe, encodingBase64, "token=abc>>>???~~~"},
+		{"hex", "value: " + hexed, encodingHex, "PASSWORD=hunter22"},
+		{"binary", "cert: " + binary, "", ""},
+		{"unpadded identifier", "getUserAccountSettingsById()", "", ""},
+		{"short", "name: " + base64.StdEncoding.EncodeToString([]byte("app")), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := findEncoded(tt.line)
+			if tt.encoding == "" {
+				if len(values) != 0 {
+					t.Errorf("expected nothing decoded, got %+v", values)
+				}
+				return
+			}
+			if len(values) != 1 || values[0].Encoding != tt.encoding || values[0].Text != tt.text {
+				t.Errorf("findEncoded(%q) = %+v, want %s %q", tt.line, values, tt.encoding, tt.text)
+			}
+		})
+	}
+}
+
+func TestScanReaderDecodesEncodedValues(t *testing.T) {
+	opts := testScanOptions(t, scanOptions{Decode: true})
+
+	password := base64.StdEncoding.EncodeToString([]byte("supersecretpassword1"))
+	config := base64.StdEncoding.EncodeToString([]byte("HOST=db\nDB_PASSWORD=\"mysecretpassword123\"\n"))
+	hexed := hex.EncodeToString([]byte(`TOKEN="anothersecretvalue9"`))
+	content := strings.Join([]string{
+		"apiVersion: v1",
+		"kind: Secret",
+		"data:",
+		"  password: " + password,
+		"  config: " + config,
+		"  token: " + hexed,
+		"  name: " + base64.StdEncoding.EncodeToString([]byte("my-application-name")),
+	}, "\n")
+
+	res := scanReader(opts, "secret.yaml", strings.NewReader(content))
+	want := []struct {
+		line      int
+		matchText string
+		encoding  string
+		encoded   string
+	}{
+		{4, "supersecretpassword1", encodingBase64, password},
+		{5, `PASSWORD="mysecretpassword123"`, encodingBase64, config},
+		{6, `TOKEN="anothersecretvalue9"`, encodingHex, hexed},
+	}
+	if len(res.Matches) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), res.Matches)
+	}
+	for i, w := range want {
+		m := res.Matches[i]
+		if m.Line != w.line || m.Encoded != w.encoding || !strings.Contains(m.MatchText, w.matchText) {
+			t.Errorf("finding %d = line %d %s %q, want line %d %s %q", i, m.Line, m.Encoded, m.MatchText, w.line, w.encoding, w.matchText)
+		}
+		lineText := strings.Split(content, "\n")[m.Line-1]
+		if got := lineText[m.Column-1 : m.EndColumn-1]; got != w.encoded {
+			t.Errorf("finding %d columns locate %q, want the encoded value", i, got)
+		}
+	}
+
+	// Redaction masks the encoded value in the snippet as well.
+	for _, m := range RedactMatches(res.Matches) {
+		if strings.Contains(m.LineSnippet, password) || strings.Contains(m.MatchText, "supersecretpassword1") {
+			t.Errorf("secret left visible after redaction: %+v", m)
+		}
+	}
+
+	opts.Decode = false
+	res = scanReader(opts, "secret.yaml", strings.NewReader(content))
+	for _, m := range res.Matches {
+		if m.Encoded != "" {
+			t.Errorf("expected no decoded findings without Decode, got %+v", m)
+		}
+	}
+}
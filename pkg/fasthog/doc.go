@@ -0,0 +1,24 @@
+// Package fasthog finds secrets and credentials in source code. It is the
+// scanning engine of the fasthog command, for tools that embed it rather
+// than running the binary.
+//
+// A Scanner combines the detection patterns, loaded with LoadPatterns or
+// embedded by default, with Options selecting the files to scan and the
+// optional detectors:
+//
+//	scanner, err := fasthog.New(nil, fasthog.DefaultOptions())
+//	if err != nil {
+//		return err
+//	}
+//	res, err := scanner.ScanFS(ctx, os.DirFS("."))
+//
+// ScanFS, ScanDirectory and ScanPaths walk files; ScanReader scans a single
+// stream; ScanGitHistory and ScanStaged scan the lines added in a git
+// repository. Each returns a Result holding every finding. Options.OnMatch
+// streams findings line by line as they are found, and Options.OnFile
+// reports progress.
+//
+// Patterns are regular expressions in the same line-oriented files the
+// command uses, with "# @rule" annotations naming the rule of the pattern
+// that follows and "[files: glob]" sections scoping patterns to file names.
+package fasthog
@@ -0,0 +1,120 @@
+package fasthog
+
+import (
+	"math"
+	"regexp"
+)
+
+// Default thresholds for the entropy detector, in bits per character. They
+// follow the values popularized by truffleHog: random base64 approaches 6
+// bits, random hex 4 bits, while English identifiers stay well below both.
+const (
+	defaultEntropyBase64Threshold = 4.5
+	defaultEntropyHexThreshold    = 3.0
+	defaultEntropyMinLength       = 20
+)
+
+// Rules reported by the entropy detector.
+var (
+	entropyBase64Rule = Rule{
+		ID:          "high-entropy-base64",
+		Description: "High-entropy base64 string in a quoted or assigned value",
+		SecretType:  "high-entropy",
+		Severity:    SeverityMedium,
+	}
+	entropyHexRule = Rule{
+		ID:          "high-entropy-hex",
+		Description: "High-entropy hex string in a quoted or assigned value",
+		SecretType:  "high-entropy",
+		Severity:    SeverityMedium,
+	}
+)
+
+// EntropyOptions configures the entropy detector.
+type EntropyOptions struct {
+	Enabled         bool
+	Base64Threshold float64
+	HexThreshold    float64
+	MinLength       int
+}
+
+// DefaultEntropyOptions returns the detector defaults, disabled.
+func DefaultEntropyOptions() EntropyOptions {
+	return EntropyOptions{
+		Base64Threshold: defaultEntropyBase64Threshold,
+		HexThreshold:    defaultEntropyHexThreshold,
+		MinLength:       defaultEntropyMinLength,
+	}
+}
+
+// entropyCandidate matches runs of base64/base64url characters that follow a
+// quote or an assignment operator, i.e. values rather than identifiers.
+var entropyCandidate = regexp.MustCompile("(?:[\"'`]|[:=]>?\\s*)([A-Za-z0-9+/_-]+={0,2})")
+
+// hexString matches strings consisting only of hex digits.
+var hexString = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+
+// entropyMatch is a candidate value whose entropy exceeded its threshold.
+type entropyMatch struct {
+	Loc     []int
+	Rule    *Rule
+	Entropy float64
+}
+
+// findHighEntropy returns the quoted or assigned values in line whose Shannon
+// entropy exceeds the threshold for their character set.
+func findHighEntropy(opts EntropyOptions, line string) []entropyMatch {
+	minLength := opts.MinLength
+	if minLength <= 0 {
+		minLength = defaultEntropyMinLength
+	}
+
+	var matches []entropyMatch
+	for _, sub := range entropyCandidate.FindAllStringSubmatchIndex(line, -1) {
+		value := line[sub[2]:sub[3]]
+		if len(value) < minLength {
+			continue
+		}
+
+		rule, threshold := &entropyBase64Rule, opts.Base64Threshold
+		if hexString.MatchString(value) {
+			rule, threshold = &entropyHexRule, opts.HexThreshold
+		}
+		if threshold <= 0 {
+			continue
+		}
+
+		if score := shannonEntropy(value); score > threshold {
+			matches = append(matches, entropyMatch{Loc: sub[2:4], Rule: rule, Entropy: score})
+		}
+	}
+	return matches
+}
+
+// roundEntropy rounds an entropy score for reporting.
+func roundEntropy(e float64) float64 {
+	return math.Round(e*1000) / 1000
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per byte.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	n := float64(len(s))
+	var entropy float64
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,118 @@
+package fasthog
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"", 0},
+		{"aaaaaaaa", 0},
+		{"abababab", 1},
+		{"0123456789abcdef", 4},
+	}
+
+	for _, tt := range tests {
+		if got := shannonEntropy(tt.input); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("shannonEntropy(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFindHighEntropy(t *testing.T) {
+	opts := DefaultEntropyOptions()
+
+	tests := []struct {
+		name     string
+		line     string
+		wantRule string
+	}{
+		{"quoted base64", `const blob = "hJ8kQ2mZx7Lp4Vn9Rt1Wc6Yb3Fd5Gs0A";`, "high-entropy-base64"},
+		{"assigned base64", `upload_sig=Zm9vYmFyYmF6cXV4MTIzNDU2Nzg5MGFiY2RlZmdoaWprbG1u`, "high-entropy-base64"},
+		{"yaml hex", `fingerprint: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b`, "high-entropy-hex"},
+		{"english identifier", `name = "thisIsAVeryLongVariableName"`, ""},
+		{"too short", `x = "aZ3$kQ9"`, ""},
+		{"unassigned word", `see hJ8kQ2mZx7Lp4Vn9Rt1Wc6Yb3Fd5Gs0A for details`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := findHighEntropy(opts, tt.line)
+			if tt.wantRule == "" {
+				if len(matches) != 0 {
+					t.Errorf("expected no matches, got %+v", matches)
+				}
+				return
+			}
+			if len(matches) != 1 {
+				t.Fatalf("expected 1 match, got %+v", matches)
+			}
+			if matches[0].Rule.ID != tt.wantRule {
+				t.Errorf("expected rule %s, got %s", tt.wantRule, matches[0].Rule.ID)
+			}
+			value := tt.line[matches[0].Loc[0]:matches[0].Loc[1]]
+			if matches[0].Entropy != shannonEntropy(value) {
+				t.Errorf("entropy %v does not match value %q", matches[0].Entropy, value)
+			}
+		})
+	}
+
+	t.Run("zero threshold disables charset", func(t *testing.T) {
+		noHex := opts
+		noHex.HexThreshold = 0
+		if matches := findHighEntropy(noHex, `fingerprint: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b`); len(matches) != 0 {
+			t.Errorf("expected hex detection to be disabled, got %+v", matches)
+		}
+	})
+}
+
+func TestScanDirectoryEntropy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := "const blob = \"hJ8kQ2mZx7Lp4Vn9Rt1Wc6Yb3Fd5Gs0A\";\n" +
+		"PASSWORD=\"mysecretpassword123\"\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "app.js"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testScanOptions(t, scanOptions{
+		Directory:  tmpDir,
+		Extensions: []string{".js"},
+	})
+
+	withoutEntropy := scanDirectory(opts)
+	for _, m := range withoutEntropy.Matches {
+		if m.Line == 1 {
+			t.Fatalf("line 1 unexpectedly matched by regex rules; pick a different fixture: %+v", m)
+		}
+	}
+
+	opts.Entropy = DefaultEntropyOptions()
+	opts.Entropy.Enabled = true
+	result := scanDirectory(opts)
+
+	var entropyMatch *Match
+	for i, m := range result.Matches {
+		if m.Entropy <= 0 {
+			t.Errorf("expected every match to record its entropy, got %+v", m)
+		}
+		if m.RuleID == entropyBase64Rule.ID {
+			entropyMatch = &result.Matches[i]
+		}
+		if m.Line == 2 && m.RuleID == entropyBase64Rule.ID {
+			t.Errorf("entropy finding duplicates a regex finding: %+v", m)
+		}
+	}
+	if entropyMatch == nil || entropyMatch.Line != 1 {
+		t.Fatalf("expected an entropy finding on line 1, got %+v", result.Matches)
+	}
+	if entropyMatch.MatchText != "hJ8kQ2mZx7Lp4Vn9Rt1Wc6Yb3Fd5Gs0A" {
+		t.Errorf("unexpected entropy match text %q", entropyMatch.MatchText)
+	}
+}
@@ -0,0 +1,66 @@
+package fasthog
+
+import (
+	"bytes"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultFilenames are base-name globs of files scanned alongside
+// DefaultExtensions: build and environment files that have no extension, or
+// whose extension says nothing about their content.
+var DefaultFilenames = []string{
+	"Dockerfile", "Dockerfile.*", "*.dockerfile", "Containerfile",
+	"Jenkinsfile", "Makefile", "GNUmakefile", "Vagrantfile", "Procfile",
+	"docker-compose.override", ".envrc", ".env.*", ".netrc", ".pgpass",
+	".pypirc", ".git-credentials", ".bashrc", ".bash_profile", ".zshrc",
+	".profile",
+}
+
+// sniffLength is how many leading bytes of an extensionless file are read to
+// decide whether it is text.
+const sniffLength = 512
+
+// matchesFilename reports whether the base name of the slash-separated path
+// matches one of the path.Match patterns, ignoring case.
+func matchesFilename(p string, patterns []string) bool {
+	base := strings.ToLower(path.Base(p))
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		ok, _ := path.Match(strings.ToLower(pattern), base)
+		return ok
+	})
+}
+
+// isExtensionless reports whether the base name of the slash-separated path
+// has no extension. The leading dot of a dotfile such as .envrc does not
+// start an extension.
+func isExtensionless(p string) bool {
+	base := path.Base(p)
+	return !strings.Contains(strings.TrimPrefix(base, "."), ".")
+}
+
+// looksLikeText reports whether data, the start of a file, is text: a script
+// starting with a shebang, or anything isBinary does not consider binary.
+func looksLikeText(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("#!")) || !isBinary(data)
+}
+
+// sniffText reads the start of the file name with open and reports whether
+// it looks like text.
+func sniffText(name string, open func(path string) (io.ReadCloser, error)) (bool, error) {
+	f, err := open(name)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close() // Read-only; nothing to flush
+	}()
+	buf := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return looksLikeText(buf[:n]), nil
+}
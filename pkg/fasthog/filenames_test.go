@@ -0,0 +1,119 @@
+package fasthog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMatchesFilename(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"Dockerfile", true},
+		{"build/dockerfile", true},
+		{"build/Dockerfile.prod", true},
+		{"api.dockerfile", true},
+		{"ci/Jenkinsfile", true},
+		{"Makefile", true},
+		{".envrc", true},
+		{"config/.env.local", true},
+		{"docker-compose.override", true},
+		{"Makefile.bak", false},
+		{"main.go", false},
+		{"README", false},
+	}
+	for _, tt := range tests {
+		if got := matchesFilename(tt.path, DefaultFilenames); got != tt.want {
+			t.Errorf("matchesFilename(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIsExtensionless(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"scripts/deploy", true},
+		{".envrc", true},
+		{"a.b/run", true},
+		{"deploy.sh", false},
+		{".env.local", false},
+	}
+	for _, tt := range tests {
+		if got := isExtensionless(tt.path); got != tt.want {
+			t.Errorf("isExtensionless(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLooksLikeText(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"shebang", "#!/bin/sh\nexport TOKEN=x\n", true},
+		{"plain text", "host=db\nuser=admin\n", true},
+		{"empty", "", true},
+		{"cut off character", "caf\xc3", true},
+		{"NUL byte", "\x7fELF\x02\x01\x01\x00\x00", false},
+		{"invalid UTF-8", "\xff\xfe\xfd\xfc\xfb text", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := looksLikeText([]byte(tt.data)); got != tt.want {
+				t.Errorf("looksLikeText(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanDirectoryFilenamesAndSniffing(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Dockerfile":       "ENV API_KEY=\"sk_live_abcdef1234567890\"\n",
+		".envrc":           "export PASSWORD=\"mysecretpassword123\"\n",
+		"scripts/deploy":   "#!/bin/sh\nTOKEN=\"anothersecretvalue9\"\n",
+		"bin/tool":         "\x7fELF\x00\x00PASSWORD=\"mysecretpassword123\"\n",
+		"notes.bin":        "PASSWORD=\"mysecretpassword123\"\n",
+		"vendor/configure": "#!/bin/sh\nTOKEN=\"anothersecretvalue9\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := testScanOptions(t, scanOptions{
+		Directory:   dir,
+		Extensions:  []string{".py"},
+		ExcludeDirs: []string{"vendor"},
+	})
+
+	res := scanDirectory(opts)
+	if len(res.Filenames) != 0 {
+		t.Errorf("without filenames or sniffing, expected nothing scanned, got %v", res.Filenames)
+	}
+
+	opts.Filenames = DefaultFilenames
+	opts.SniffExtensionless = true
+	res = scanDirectory(opts)
+	got := slices.Sorted(slices.Values(res.Filenames))
+	want := []string{".envrc", "Dockerfile", "scripts/deploy"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got scanned files %q, want %q", got, want)
+	}
+	for _, name := range want {
+		if res.MatchFiles[name] == 0 {
+			t.Errorf("expected a finding in %s, got %v", name, res.MatchFiles)
+		}
+	}
+}
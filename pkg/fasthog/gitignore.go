@@ -0,0 +1,231 @@
+package fasthog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// fasthogIgnoreFile is read from the scan root to exclude paths from every
+// scan, using gitignore syntax.
+const fasthogIgnoreFile = ".fasthogignore"
+
+// gitIgnoreFile is read from every directory when .gitignore files are
+// honored.
+const gitIgnoreFile = ".gitignore"
+
+// ignorePattern is one compiled line of a gitignore-syntax file.
+type ignorePattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreFile holds the patterns of one ignore file, which apply to paths
+// below its directory.
+type ignoreFile struct {
+	// dir is the slash-separated directory of the file relative to the scan
+	// root, or "." for the root.
+	dir      string
+	patterns []ignorePattern
+}
+
+// pathIgnorer decides which paths gitignore-syntax files exclude. Like git,
+// the last matching pattern wins, patterns in deeper .gitignore files take
+// precedence over shallower ones, and .fasthogignore takes precedence over
+// them all.
+type pathIgnorer struct {
+	fsys      fs.FS
+	gitignore bool
+
+	files   []ignoreFile
+	fasthog *ignoreFile
+}
+
+// newPathIgnorer loads .fasthogignore from the root of fsys. If gitignore is
+// set, .gitignore files are added with enterDir, starting with the root, as
+// a walk reaches their directories. The returned ignorer is usable even if
+// the file could not be read.
+func newPathIgnorer(fsys fs.FS, gitignore bool) (*pathIgnorer, error) {
+	pi := &pathIgnorer{fsys: fsys, gitignore: gitignore}
+	f, err := loadIgnoreFile(fsys, ".", fasthogIgnoreFile)
+	pi.fasthog = f
+	return pi, err
+}
+
+// enterDir loads the .gitignore file of dir, if .gitignore files are
+// honored and it has one.
+func (pi *pathIgnorer) enterDir(dir string) error {
+	if !pi.gitignore {
+		return nil
+	}
+	f, err := loadIgnoreFile(pi.fsys, dir, gitIgnoreFile)
+	if f != nil {
+		pi.files = append(pi.files, *f)
+	}
+	return err
+}
+
+// ignored reports whether the slash-separated path p, relative to the scan
+// root, is excluded. Parent directories are not consulted, as a walk skips
+// the contents of ignored directories; use ignoredPath otherwise.
+func (pi *pathIgnorer) ignored(p string, isDir bool) bool {
+	ignored := false
+	check := func(f *ignoreFile) {
+		rel := p
+		if f.dir != "." {
+			if !strings.HasPrefix(p, f.dir+"/") {
+				return
+			}
+			rel = p[len(f.dir)+1:]
+		}
+		for _, pat := range f.patterns {
+			if (!pat.dirOnly || isDir) && pat.re.MatchString(rel) {
+				ignored = !pat.negate
+			}
+		}
+	}
+	for i := range pi.files {
+		check(&pi.files[i])
+	}
+	if pi.fasthog != nil {
+		check(pi.fasthog)
+	}
+	return ignored
+}
+
+// ignoredPath reports whether the file p, or any directory containing it, is
+// excluded.
+func (pi *pathIgnorer) ignoredPath(p string) bool {
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' && pi.ignored(p[:i], true) {
+			return true
+		}
+	}
+	return pi.ignored(p, false)
+}
+
+// loadIgnoreFile parses the ignore file name in dir, returning nil if it
+// does not exist.
+func loadIgnoreFile(fsys fs.FS, dir, name string) (*ignoreFile, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ignoreFile{dir: dir, patterns: parseIgnorePatterns(data)}, nil
+}
+
+// parseIgnorePatterns compiles the lines of a gitignore-syntax file.
+func parseIgnorePatterns(data []byte) []ignorePattern {
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if pat, ok := parseIgnorePattern(scanner.Text()); ok {
+			patterns = append(patterns, pat)
+		}
+	}
+	return patterns
+}
+
+// parseIgnorePattern compiles one gitignore line. Blank lines and comments
+// yield no pattern.
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped with a backslash.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || line[0] == '#' {
+		return ignorePattern{}, false
+	}
+
+	var pat ignorePattern
+	if line[0] == '!' {
+		pat.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pat.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	// A slash anywhere but at the end anchors the pattern to the ignore
+	// file's directory; otherwise it matches a name at any depth.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	pat.re = re
+	return pat, true
+}
+
+// globToRegexp translates a gitignore glob into a regular expression: "*"
+// and "?" do not match "/", "[...]" is a character class, a leading "**/"
+// or inner "/**/" matches any number of directories and a trailing "/**"
+// matches everything inside.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/') {
+				switch {
+				case i+2 == len(glob):
+					b.WriteString(".*")
+					i++
+					continue
+				case glob[i+2] == '/':
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package fasthog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestIgnorePatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"*.log", "debug.log.txt", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"build/", "src/build", true, true},
+		{"/config.env", "config.env", false, true},
+		{"/config.env", "app/config.env", false, false},
+		{"fixtures/*.json", "fixtures/a.json", false, true},
+		{"fixtures/*.json", "test/fixtures/a.json", false, false},
+		{"fixtures/*.json", "fixtures/deep/a.json", false, false},
+		{"**/fixtures", "a/b/fixtures", true, true},
+		{"**/fixtures", "fixtures", true, true},
+		{"a/**/z.py", "a/z.py", false, true},
+		{"a/**/z.py", "a/b/c/z.py", false, true},
+		{"generated/**", "generated/x/y.go", false, true},
+		{"secret?.txt", "secret1.txt", false, true},
+		{"secret?.txt", "secret12.txt", false, false},
+		{"key[0-9].pem", "key7.pem", false, true},
+		{"key[!0-9].pem", "key7.pem", false, false},
+		{`\#hash`, "#hash", false, true},
+		{"# comment", "# comment", false, false},
+	}
+
+	for _, tt := range tests {
+		pat, ok := parseIgnorePattern(tt.pattern)
+		got := ok && (!pat.dirOnly || tt.isDir) && pat.re.MatchString(tt.path)
+		if got != tt.want {
+			t.Errorf("pattern %q on %q (dir=%v) = %v, want %v", tt.pattern, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestPathIgnorerPrecedence(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore":       {Data: []byte("*.env\n!keep.env\n/dist/\n")},
+		"app/.gitignore":   {Data: []byte("!local.env\n")},
+		".fasthogignore":   {Data: []byte("testdata/\nkeep.env\n")},
+		"app/local.env":    {Data: nil},
+		"app/other.env":    {Data: nil},
+		"keep.env":         {Data: nil},
+		"dist/bundle.js":   {Data: nil},
+		"app/dist/main.js": {Data: nil},
+	}
+
+	pi, err := newPathIgnorer(fsys, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{".", "app"} {
+		if err := pi.enterDir(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"app/other.env", true},
+		{"app/local.env", false},          // re-included by the nested file
+		{"keep.env", true},                // re-excluded by .fasthogignore
+		{"dist/bundle.js", true},          // anchored directory
+		{"app/dist/main.js", false},       // anchored to the root only
+		{"app/testdata/fixture.py", true}, // directory from .fasthogignore
+		{"app/src/main.py", false},
+	}
+	for _, tt := range tests {
+		if got := pi.ignoredPath(tt.path); got != tt.want {
+			t.Errorf("ignoredPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	t.Run("gitignore is opt-in", func(t *testing.T) {
+		pi, err := newPathIgnorer(fsys, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pi.enterDir("."); err != nil {
+			t.Fatal(err)
+		}
+		if pi.ignoredPath("app/other.env") || !pi.ignoredPath("keep.env") {
+			t.Error("only .fasthogignore should apply without gitignore")
+		}
+	})
+}
+
+func TestScanDirectoryHonorsIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := "PASSWORD=\"mysecretpassword123\"\n"
+	files := map[string]string{
+		".gitignore":                 "generated/\n",
+		".fasthogignore":             "/tests/fixtures/**/*.py\n",
+		"app/config.py":              secret,
+		"generated/out.py":           secret,
+		"tests/fixtures/creds.py":    secret,
+		"tests/fixtures/nested/x.py": secret,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := testScanOptions(t, scanOptions{
+		Directory:  dir,
+		Extensions: []string{".py"},
+	})
+
+	res := scanDirectory(opts)
+	if want := []string{"app/config.py", "generated/out.py"}; !slices.Equal(sortedFilenames(res), want) {
+		t.Errorf("without --gitignore scanned %v, want %v", sortedFilenames(res), want)
+	}
+
+	opts.Gitignore = true
+	res = scanDirectory(opts)
+	if want := []string{"app/config.py"}; !slices.Equal(sortedFilenames(res), want) {
+		t.Errorf("with --gitignore scanned %v, want %v", sortedFilenames(res), want)
+	}
+}
+
+func sortedFilenames(res Result) []string {
+	names := slices.Clone(res.Filenames)
+	slices.Sort(names)
+	return names
+}
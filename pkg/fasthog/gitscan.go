@@ -0,0 +1,384 @@
+package fasthog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommitInfo identifies the commit that introduced a finding in
+// --git-history mode.
+type CommitInfo struct {
+	SHA    string    `json:"sha"`
+	Author string    `json:"author"`
+	Email  string    `json:"email"`
+	Date   time.Time `json:"date"`
+}
+
+// ShortSHA returns the abbreviated commit hash used in text output.
+func (c *CommitInfo) ShortSHA() string {
+	if len(c.SHA) > 12 {
+		return c.SHA[:12]
+	}
+	return c.SHA
+}
+
+// GitHistoryOptions selects the commits scanned in --git-history mode.
+type GitHistoryOptions struct {
+	// Branches lists the revisions whose history is walked. Empty means HEAD.
+	Branches []string
+	// Since and Until bound commit dates in any format git log accepts,
+	// such as "2024-01-31" or "2 weeks ago".
+	Since string
+	Until string
+}
+
+// gitCommitMarker starts each commit header in the log output, written as
+// %x00 in the format. No diff line starts with a NUL byte, so headers cannot
+// be confused with content.
+const gitCommitMarker = "\x00"
+
+// gitHunkHeader captures the starting line in the new file from a unified
+// diff hunk header.
+var gitHunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// Revisions returns the revisions to walk, rejecting values git would parse
+// as options.
+func (h GitHistoryOptions) Revisions() ([]string, error) {
+	if len(h.Branches) == 0 {
+		return []string{"HEAD"}, nil
+	}
+	for _, b := range h.Branches {
+		if b == "" || strings.HasPrefix(b, "-") {
+			return nil, fmt.Errorf("invalid branch %q", b)
+		}
+	}
+	return h.Branches, nil
+}
+
+// filterArgs returns the git options limiting commits by date.
+func (h GitHistoryOptions) filterArgs() []string {
+	var args []string
+	if h.Since != "" {
+		args = append(args, "--since="+h.Since)
+	}
+	if h.Until != "" {
+		args = append(args, "--until="+h.Until)
+	}
+	return args
+}
+
+// runGit runs git in dir and returns its standard output.
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// ValidateGitRepo checks that git is available and that dir lies inside a
+// git repository. flag names the option that needs git, for the error message.
+func ValidateGitRepo(dir, flag string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("%s requires the git executable on PATH", flag)
+	}
+	if _, err := runGit(dir, "rev-parse", "--git-dir"); err != nil {
+		return fmt.Errorf("%s is not a git repository: %w", dir, err)
+	}
+	return nil
+}
+
+// ValidateGitHistory checks that dir is a git repository and that the
+// requested revisions exist.
+func ValidateGitHistory(dir string, hist GitHistoryOptions) error {
+	if err := ValidateGitRepo(dir, "--git-history"); err != nil {
+		return err
+	}
+	revs, err := hist.Revisions()
+	if err != nil {
+		return err
+	}
+	for _, rev := range revs {
+		if _, err := runGit(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+			return fmt.Errorf("unknown branch or revision %q", rev)
+		}
+	}
+	return nil
+}
+
+// scanGitHistory scans the lines added by every commit reachable from the
+// selected branches, attributing each finding to its commit. Files are
+// filtered by extension and excluded directories as in scanDirectory.
+func scanGitHistory(opts scanOptions, hist GitHistoryOptions) (Result, error) {
+	revs, err := hist.Revisions()
+	if err != nil {
+		return Result{}, err
+	}
+
+	countOut, err := runGit(opts.Directory, append(append([]string{"rev-list", "--count"}, hist.filterArgs()...), revs...)...)
+	if err != nil {
+		return Result{}, err
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(string(countOut)))
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected git rev-list output %q", countOut)
+	}
+
+	args := []string{
+		"log", "--patch", "--format=%x00%H%x00%an%x00%ae%x00%aI",
+	}
+	args = append(args, hist.filterArgs()...)
+	args = append(args, revs...)
+	return scanGitPatch(opts, total, args...)
+}
+
+// scanStaged scans the lines added or modified in the index, that is, the
+// changes that are about to be committed. Files are filtered by extension and
+// excluded directories as in scanDirectory.
+func scanStaged(opts scanOptions) (Result, error) {
+	// Deleted files add no lines, so there is nothing to scan in them.
+	return scanGitPatch(opts, 0, "diff", "--cached", "--diff-filter=d")
+}
+
+// gitPatchArgs make the diff output of git log and git diff predictable
+// regardless of user configuration: no context lines, colour, external diff
+// drivers or text conversion, fixed a/ and b/ prefixes, and unquoted
+// non-ASCII paths.
+var gitPatchArgs = []string{
+	"--unified=0", "--no-color", "--no-ext-diff", "--no-textconv",
+	"--src-prefix=a/", "--dst-prefix=b/",
+}
+
+// scanGitPatch runs the git log or git diff command given by args in the
+// scanned directory, adding gitPatchArgs, and scans the added lines of its
+// patch output with scanGitLog.
+func scanGitPatch(opts scanOptions, total int, args ...string) (Result, error) {
+	name := args[0]
+	cmdArgs := []string{"-C", opts.Directory, "-c", "core.quotePath=false", name}
+	cmdArgs = append(cmdArgs, gitPatchArgs...)
+	cmdArgs = append(cmdArgs, args[1:]...)
+	cmdArgs = append(cmdArgs, "--")
+
+	cmd := exec.Command("git", cmdArgs...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return Result{}, fmt.Errorf("git %s: %w", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return Result{}, fmt.Errorf("git %s: %w", name, err)
+	}
+
+	res, scanErr := scanGitLog(opts, stdout, total)
+	if scanErr != nil {
+		_ = cmd.Process.Kill() // Stop git writing to a pipe nobody reads
+	}
+	waitErr := cmd.Wait()
+	if scanErr != nil {
+		return res, scanErr
+	}
+	if waitErr != nil {
+		return res, fmt.Errorf("git %s: %w: %s", name, waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return res, nil
+}
+
+// scanGitLog parses the output of git log --patch --unified=0 in the format
+// produced by scanGitHistory, or of git diff --unified=0, and scans each
+// added line. total is the number of commits expected, for progress
+// reporting; without commit headers, progress is reported per file and total
+// may be zero if unknown.
+func scanGitLog(opts scanOptions, r io.Reader, total int) (Result, error) {
+	result := Result{MatchFiles: make(map[string]int)}
+	excludeDirs := mergeExcludeDirs(opts.ExcludeDirs)
+
+	// Only the ignore files at the root of the working tree, if there is
+	// one, apply to diffs.
+	ignorer := &pathIgnorer{}
+	if opts.Directory != "" {
+		var err error
+		ignorer, err = newPathIgnorer(os.DirFS(opts.Directory), opts.Gitignore)
+		if err == nil {
+			err = ignorer.enterDir(".")
+		}
+		if err != nil {
+			return result, fmt.Errorf("reading ignore files: %w", err)
+		}
+	}
+	seen := make(map[string]bool)
+
+	var (
+		commit      *CommitInfo
+		commitIndex = -1
+		ls          *lineScanner
+		// inHunk is false while reading a file's diff header.
+		inHunk bool
+		path   string
+		lineNo int
+	)
+
+	endFile := func() {
+		if ls == nil {
+			return
+		}
+		fileRes := ls.finish()
+		result.Matches = append(result.Matches, fileRes.Matches...)
+		if len(fileRes.Matches) > 0 {
+			result.MatchFiles[ls.path] += len(fileRes.Matches)
+		}
+		result.Suppressed += fileRes.Suppressed
+		result.Ignored += fileRes.Ignored
+		result.Placeholders = append(result.Placeholders, fileRes.Placeholders...)
+		for _, w := range fileRes.Warnings {
+			if commit != nil {
+				w.File = commit.ShortSHA() + ":" + w.File
+			}
+			result.Warnings = append(result.Warnings, w)
+		}
+		ls = nil
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return result, fmt.Errorf("reading git log: %w", err)
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		switch {
+		case strings.HasPrefix(line, gitCommitMarker):
+			endFile()
+			c, parseErr := parseCommitHeader(line)
+			if parseErr != nil {
+				return result, parseErr
+			}
+			commit = c
+			commitIndex++
+			inHunk, path = false, ""
+
+		case strings.HasPrefix(line, "diff --git "):
+			endFile()
+			if err := opts.contextErr(); err != nil {
+				return result, err
+			}
+			inHunk, path = false, ""
+
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			path = parseDiffPath(strings.TrimPrefix(line, "+++ "))
+			// Git omits binary files from patches, so every extensionless
+			// file in one is text.
+			included := includePath(path, opts.Extensions, opts.Filenames, excludeDirs) ||
+				(opts.SniffExtensionless && isExtensionless(path) && !inExcludedDir(path, excludeDirs))
+			if path == "" || !included || ignorer.ignoredPath(path) {
+				path = ""
+				break
+			}
+			if !seen[path] {
+				seen[path] = true
+				result.Filenames = append(result.Filenames, path)
+			}
+			if opts.OnCurrentFile != nil {
+				index := commitIndex
+				if commit == nil {
+					index = len(result.Filenames) - 1
+				}
+				opts.OnCurrentFile(path, index, total)
+			}
+			ls = newLineScanner(opts, path)
+			ls.commit = commit
+
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			m := gitHunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return result, fmt.Errorf("unexpected diff hunk header %q", line)
+			}
+			lineNo, _ = strconv.Atoi(m[1])
+			if ls != nil {
+				// Hunks are not contiguous, so a key block cannot span them.
+				ls.breakBlock()
+			}
+
+		case inHunk && strings.HasPrefix(line, "+"):
+			if ls != nil {
+				scanAddedLine(ls, lineNo, line[1:])
+			}
+			lineNo++
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	endFile()
+
+	return result, nil
+}
+
+// scanAddedLine feeds one added line to ls, splitting it into windows if it
+// is longer than the scanner's maximum line length.
+func scanAddedLine(ls *lineScanner, lineNo int, text string) {
+	reader := newLineReader(strings.NewReader(text), ls.maxLineLength)
+	for {
+		chunk, err := reader.next()
+		if err != nil {
+			return
+		}
+		chunk.LineNo = lineNo
+		ls.scan(chunk)
+	}
+}
+
+// parseCommitHeader parses a commit header line written with the format
+// marker, hash, author name, author email and strict ISO 8601 author date,
+// separated by NUL bytes.
+func parseCommitHeader(line string) (*CommitInfo, error) {
+	fields := strings.Split(strings.TrimPrefix(line, gitCommitMarker), "\x00")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected git log header %q", line)
+	}
+	date, err := time.Parse(time.RFC3339, fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected commit date in git log header: %w", err)
+	}
+	return &CommitInfo{SHA: fields[0], Author: fields[1], Email: fields[2], Date: date}, nil
+}
+
+// parseDiffPath extracts the new path from the argument of a "+++" diff
+// header, returning "" for deleted files.
+func parseDiffPath(s string) string {
+	// Git appends a tab to paths containing spaces.
+	s = strings.TrimSuffix(s, "\t")
+	if s == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(s, `"`) {
+		// Paths with special characters are C-quoted, which is compatible
+		// with Go string literal syntax for the escapes git emits.
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return ""
+		}
+		s = unquoted
+	}
+	return strings.TrimPrefix(s, "b/")
+}
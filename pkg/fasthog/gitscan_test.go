@@ -0,0 +1,285 @@
+package fasthog
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// gitTestRepo is a throwaway repository with deterministic commit metadata.
+type gitTestRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newGitTestRepo(t *testing.T) *gitTestRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	r := &gitTestRepo{t: t, dir: t.TempDir()}
+	r.git("", "init", "--quiet", "--initial-branch=main")
+	return r
+}
+
+func (r *gitTestRepo) git(date string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=Dana Dev", "GIT_AUTHOR_EMAIL=dana@example.com",
+		"GIT_COMMITTER_NAME=Dana Dev", "GIT_COMMITTER_EMAIL=dana@example.com",
+		"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes files (an empty content deletes the file) and commits them
+// at date, returning the commit hash.
+func (r *gitTestRepo) commit(date, message string, files map[string]string) string {
+	r.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(r.dir, name)
+		if content == "" {
+			if err := os.Remove(path); err != nil {
+				r.t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.git(date, "add", "-A")
+	r.git(date, "commit", "--quiet", "-m", message)
+	return r.git(date, "rev-parse", "HEAD")
+}
+
+func historyScanOptions(t *testing.T, dir string) scanOptions {
+	t.Helper()
+	return testScanOptions(t, scanOptions{
+		Directory:  dir,
+		Extensions: []string{".env", ".py"},
+	})
+}
+
+func TestScanGitHistory(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.commit("2024-01-10T12:00:00Z", "initial", map[string]string{
+		"app.py": "print('hello')\n",
+	})
+	leaked := repo.commit("2024-02-10T12:00:00Z", "add config", map[string]string{
+		"config/app.env": "DEBUG=true\nPASSWORD=\"mysecretpassword123\"\n",
+		"vendor/lib.py":  "PASSWORD=\"vendoredsecret1234\"\n",
+	})
+	repo.commit("2024-03-10T12:00:00Z", "remove secret", map[string]string{
+		"config/app.env": "DEBUG=true\n",
+	})
+
+	opts := historyScanOptions(t, repo.dir)
+
+	t.Run("deleted secret found in history", func(t *testing.T) {
+		if res := scanDirectory(opts); len(res.Matches) != 0 {
+			t.Fatalf("working tree should be clean, got %+v", res.Matches)
+		}
+
+		res, err := scanGitHistory(opts, GitHistoryOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Matches) != 1 {
+			t.Fatalf("expected 1 match (vendor excluded), got %+v", res.Matches)
+		}
+		m := res.Matches[0]
+		if m.File != "config/app.env" || m.Line != 2 {
+			t.Errorf("unexpected location %s:%d", m.File, m.Line)
+		}
+		if m.Commit == nil || m.Commit.SHA != leaked {
+			t.Fatalf("expected commit %s, got %+v", leaked, m.Commit)
+		}
+		if m.Commit.Author != "Dana Dev" || m.Commit.Email != "dana@example.com" {
+			t.Errorf("unexpected author %+v", m.Commit)
+		}
+		if got := m.Commit.Date.UTC().Format("2006-01-02"); got != "2024-02-10" {
+			t.Errorf("unexpected commit date %s", got)
+		}
+	})
+
+	t.Run("since and until", func(t *testing.T) {
+		res, err := scanGitHistory(opts, GitHistoryOptions{Since: "2024-03-01"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Matches) != 0 {
+			t.Errorf("expected no matches after the leak, got %+v", res.Matches)
+		}
+
+		res, err = scanGitHistory(opts, GitHistoryOptions{Until: "2024-01-31"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Matches) != 0 {
+			t.Errorf("expected no matches before the leak, got %+v", res.Matches)
+		}
+	})
+
+	t.Run("branch selection", func(t *testing.T) {
+		repo.git("2024-04-01T12:00:00Z", "checkout", "--quiet", "-b", "feature")
+		side := repo.commit("2024-04-01T12:00:00Z", "feature work", map[string]string{
+			"feature.py": "api_key = \"sk_live_abcdef1234567890\"\n",
+		})
+		repo.git("2024-04-01T12:00:00Z", "checkout", "--quiet", "main")
+
+		res, err := scanGitHistory(opts, GitHistoryOptions{Branches: []string{"main"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range res.Matches {
+			if m.Commit.SHA == side {
+				t.Errorf("main history should not include feature commit: %+v", m)
+			}
+		}
+
+		res, err = scanGitHistory(opts, GitHistoryOptions{Branches: []string{"feature"}, Since: "2024-03-15"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Matches) != 1 || res.Matches[0].Commit.SHA != side || res.Matches[0].File != "feature.py" {
+			t.Errorf("expected the feature commit's finding, got %+v", res.Matches)
+		}
+	})
+}
+
+func TestScanStaged(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.commit("2024-01-10T12:00:00Z", "initial", map[string]string{
+		"app.env":  "DEBUG=true\nPASSWORD=\"committedsecret123\"\n",
+		"gone.env": "PASSWORD=\"deletedsecretvalue1\"\n",
+	})
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo.dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("app.env", "DEBUG=true\nPASSWORD=\"committedsecret123\"\nTOKEN=\"stagedsecretvalue12\"\n")
+	write("new.py", "api_key = \"sk_live_abcdef1234567890\"\n")
+	write("unstaged.py", "PASSWORD=\"unstagedsecretval1\"\n")
+	repo.git("", "add", "app.env", "new.py")
+	repo.git("", "rm", "--quiet", "gone.env")
+
+	opts := historyScanOptions(t, repo.dir)
+	res, err := scanStaged(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range res.Matches {
+		if m.Commit != nil {
+			t.Errorf("staged findings should have no commit, got %+v", m.Commit)
+		}
+		got = append(got, fmt.Sprintf("%s:%d", m.File, m.Line))
+	}
+	slices.Sort(got)
+	if want := []string{"app.env:3", "new.py:1"}; !slices.Equal(got, want) {
+		t.Errorf("expected staged findings %v, got %v", want, got)
+	}
+	if want := []string{"app.env", "new.py"}; !slices.Equal(res.Filenames, want) {
+		t.Errorf("expected scanned files %v, got %v", want, res.Filenames)
+	}
+}
+
+func TestValidateGitHistory(t *testing.T) {
+	repo := newGitTestRepo(t)
+	repo.commit("2024-01-10T12:00:00Z", "initial", map[string]string{"app.py": "x = 1\n"})
+
+	tests := []struct {
+		name    string
+		dir     string
+		hist    GitHistoryOptions
+		wantErr string
+	}{
+		{"valid", repo.dir, GitHistoryOptions{}, ""},
+		{"not a repository", t.TempDir(), GitHistoryOptions{}, "not a git repository"},
+		{"unknown branch", repo.dir, GitHistoryOptions{Branches: []string{"nope"}}, "unknown branch"},
+		{"option injection", repo.dir, GitHistoryOptions{Branches: []string{"--output=/tmp/x"}}, "invalid branch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGitHistory(tt.dir, tt.hist)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScanGitLogParsing(t *testing.T) {
+	log := "\x00abc123\x00Dana Dev\x00dana@example.com\x002024-02-10T12:00:00+00:00\n" +
+		"\n" +
+		"diff --git a/my app.env b/my app.env\n" +
+		"index 1111111..2222222 100644\n" +
+		"--- a/my app.env\t\n" +
+		"+++ b/my app.env\t\n" +
+		"@@ -3,0 +4,2 @@ DEBUG=true\n" +
+		"+++ PASSWORD=\"mysecretpassword123\"\n" +
+		"+PASSWORD=\"anothersecretvalue9\"\n" +
+		"@@ -10 +12 @@\n" +
+		"-PASSWORD=\"removedsecretvalue1\"\n" +
+		"+PASSWORD=\"replacedsecretvalu2\"\n" +
+		"diff --git a/gone.env b/gone.env\n" +
+		"deleted file mode 100644\n" +
+		"--- a/gone.env\n" +
+		"+++ /dev/null\n" +
+		"@@ -1 +0,0 @@\n" +
+		"-PASSWORD=\"deletedsecretvalue1\"\n"
+
+	opts := historyScanOptions(t, "")
+	res, err := scanGitLog(opts, strings.NewReader(log), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []int
+	for _, m := range res.Matches {
+		if m.File != "my app.env" || m.Commit == nil || m.Commit.SHA != "abc123" {
+			t.Errorf("unexpected attribution %+v", m)
+		}
+		lines = append(lines, m.Line)
+	}
+	if want := []int{4, 5, 12}; !slicesEqual(lines, want) {
+		t.Errorf("expected matches on lines %v, got %v", want, lines)
+	}
+}
+
+func slicesEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package fasthog
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ignoreDirective matches inline suppression comments:
+//
+//	password = "hunter2" # fasthog:ignore
+//	// fasthog:ignore-next-line rule=generic-password,aws-secret-key
+//
+// The first group is set for ignore-next-line, the second holds the optional
+// comma-separated rule IDs the directive is restricted to.
+var ignoreDirective = regexp.MustCompile(`fasthog:ignore(-next-line)?\b(?:[ \t]+rule=([\w.-]+(?:,[\w.-]+)*))?`)
+
+// ignoreRules is the set of rules suppressed on one line by inline
+// directives; all suppresses every rule.
+type ignoreRules struct {
+	all   bool
+	rules []string
+}
+
+// covers reports whether the finding of ruleID is suppressed.
+func (ir ignoreRules) covers(ruleID string) bool {
+	return ir.all || slices.Contains(ir.rules, ruleID)
+}
+
+// add merges a directive naming rules, or every rule if rules is empty.
+func (ir *ignoreRules) add(rules string) {
+	if rules == "" {
+		ir.all = true
+		return
+	}
+	ir.rules = append(ir.rules, strings.Split(rules, ",")...)
+}
+
+// merge adds the rules suppressed by other.
+func (ir *ignoreRules) merge(other ignoreRules) {
+	ir.all = ir.all || other.all
+	ir.rules = append(ir.rules, other.rules...)
+}
+
+// parseIgnoreDirectives returns the rules suppressed on the line itself and
+// on the following line by the directives in text.
+func parseIgnoreDirectives(text string) (same, next *ignoreRules) {
+	if !strings.Contains(text, "fasthog:ignore") {
+		return nil, nil
+	}
+	for _, m := range ignoreDirective.FindAllStringSubmatch(text, -1) {
+		target := &same
+		if m[1] != "" {
+			target = &next
+		}
+		if *target == nil {
+			*target = &ignoreRules{}
+		}
+		(*target).add(m[2])
+	}
+	return same, next
+}
@@ -0,0 +1,62 @@
+package fasthog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseIgnoreDirectives(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		same, next *ignoreRules
+	}{
+		{"none", `password = "hunter2"`, nil, nil},
+		{"same line", `password = "hunter2" # fasthog:ignore`, &ignoreRules{all: true}, nil},
+		{"next line with rules", `// fasthog:ignore-next-line rule=generic-password,aws-key`, nil, &ignoreRules{rules: []string{"generic-password", "aws-key"}}},
+		{"same line with rule", `x = "y" # fasthog:ignore rule=quoted-secret-assignment`, &ignoreRules{rules: []string{"quoted-secret-assignment"}}, nil},
+		{"not a directive", `# fasthog:ignored`, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same, next := parseIgnoreDirectives(tt.line)
+			if !equalIgnoreRules(same, tt.same) || !equalIgnoreRules(next, tt.next) {
+				t.Errorf("parseIgnoreDirectives(%q) = %+v, %+v; want %+v, %+v", tt.line, same, next, tt.same, tt.next)
+			}
+		})
+	}
+}
+
+func equalIgnoreRules(a, b *ignoreRules) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.all == b.all && strings.Join(a.rules, ",") == strings.Join(b.rules, ",")
+}
+
+func TestScanReaderHonorsIgnoreDirectives(t *testing.T) {
+	opts := testScanOptions(t, scanOptions{})
+
+	content := strings.Join([]string{
+		`PASSWORD="mysecretpassword123" # fasthog:ignore`,
+		`# fasthog:ignore-next-line`,
+		`PASSWORD="anothersecretvalue9"`,
+		`PASSWORD="thirdsecretvalue123" // fasthog:ignore rule=some-other-rule`,
+		`// fasthog:ignore-next-line rule=quoted-secret-assignment`,
+		`PASSWORD="fourthsecretvalue12"`,
+		`PASSWORD="reportedsecretval12"`,
+	}, "\n")
+
+	res := scanReader(opts, "fixtures.py", strings.NewReader(content))
+	var lines []int
+	for _, m := range res.Matches {
+		lines = append(lines, m.Line)
+	}
+	if want := []int{4, 7}; !slicesEqual(lines, want) {
+		t.Errorf("expected findings on lines %v, got %v", want, lines)
+	}
+	if res.Ignored != 3 {
+		t.Errorf("expected 3 ignored findings, got %d", res.Ignored)
+	}
+}
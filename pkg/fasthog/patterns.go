@@ -0,0 +1,89 @@
+package fasthog
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//go:embed *.regex
+var regexFS embed.FS
+
+// PatternFiles describes optional overrides for the default regex pattern files.
+type PatternFiles struct {
+	Direct  string
+	Fast    string
+	Strict  string
+	Exclude string
+}
+
+// LoadPatterns loads the regex patterns used for scanning, including those
+// in sections scoped to some files, applying any file overrides specified in
+// patternFiles. When no overrides are provided, the embedded default
+// patterns are used.
+func LoadPatterns(patternFiles PatternFiles) (*Patterns, error) {
+	overrideFS := os.DirFS(".")
+
+	// Exclude patterns.
+	var exclude []scopedPattern
+	var err error
+	if patternFiles.Exclude != "" {
+		exclude, err = readPatterns(overrideFS, patternFiles.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exclude patterns from %s: %w", patternFiles.Exclude, err)
+		}
+	} else {
+		exclude, err = readPatterns(regexFS, "exclude_patterns.regex")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exclude patterns: %w", err)
+		}
+	}
+
+	// Fast patterns.
+	var fast []scopedPattern
+	if patternFiles.Fast != "" {
+		fast, err = readPatterns(overrideFS, patternFiles.Fast)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fast patterns from %s: %w", patternFiles.Fast, err)
+		}
+	} else {
+		fast, err = readPatterns(regexFS, "direct_matches.regex", "fast_patterns.regex")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fast patterns: %w", err)
+		}
+	}
+
+	// Slow (strict) patterns, loaded as named rules. If either direct or strict
+	// overrides are supplied, they fully replace the embedded slow patterns.
+	var slow []Rule
+	if patternFiles.Direct != "" || patternFiles.Strict != "" {
+		var paths []string
+		if patternFiles.Direct != "" {
+			paths = append(paths, patternFiles.Direct)
+		}
+		if patternFiles.Strict != "" {
+			paths = append(paths, patternFiles.Strict)
+		}
+
+		slow, err = readRules(overrideFS, paths...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load strict patterns from override files: %w", err)
+		}
+	} else {
+		slow, err = readRules(regexFS, "direct_matches.regex", "strict_patterns.regex")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load strict patterns: %w", err)
+		}
+	}
+
+	ps, err := newPatternSet(exclude, fast, slow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load patterns: %w", err)
+	}
+	return ps, nil
+}
+
+// shellReplacer replaces bash escape sequences in regex files to maintain
+// compatibility with the original shell script implementation.
+var shellReplacer = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
@@ -0,0 +1,234 @@
+package fasthog
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+)
+
+// Rules reported by the PEM block detector.
+var (
+	privateKeyBlockRule = Rule{
+		ID:          "private-key-block",
+		Description: "Complete PEM or PGP private key block",
+		SecretType:  "private-key",
+		Severity:    SeverityCritical,
+	}
+	privateKeyExampleRule = Rule{
+		ID:          "private-key-block-example",
+		Description: "Placeholder or truncated private key block",
+		SecretType:  "private-key",
+		Severity:    SeverityLow,
+	}
+)
+
+// pemMaxBodyLines bounds how many lines a block may span before it is
+// treated as truncated. A 4096-bit RSA key is about 50 lines of base64 and
+// armored PGP keys rarely exceed a few hundred.
+const pemMaxBodyLines = 400
+
+// pemMinKeyBytes is the smallest decoded body accepted as real key material.
+// The smallest real keys (raw EC and Ed25519) decode to well over this.
+const pemMinKeyBytes = 64
+
+var (
+	pemBegin = regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]+)-----`)
+	pemEnd   = regexp.MustCompile(`-----END ([A-Z0-9 ]+)-----`)
+
+	// pemBodyLine matches a line of base64 key material, optionally wrapped
+	// in the string quoting and concatenation used to embed keys in code. A
+	// leading + is only concatenation when a quote or space follows it;
+	// otherwise it is the first character of the base64.
+	pemBodyLine = regexp.MustCompile(`^\s*(?:\+\s*["']|\+\s+|["'])?([A-Za-z0-9+/]*={0,2})["',+;\s\\n]*$`)
+	// pemHeaderLine matches RFC 1421 and OpenPGP armor headers such as
+	// "Proc-Type: 4,ENCRYPTED" or "Version: GnuPG v2".
+	pemHeaderLine = regexp.MustCompile(`^\s*[A-Za-z][A-Za-z0-9-]*:\s`)
+	// pgpChecksumLine matches the CRC24 line that ends an armored PGP body.
+	pgpChecksumLine = regexp.MustCompile(`^\s*=[A-Za-z0-9+/]{4}\s*$`)
+
+	// pemPlaceholder matches bodies that are obviously illustrative.
+	pemPlaceholder = regexp.MustCompile(`(?i)\.\.\.|xxxx|\*\*\*|<[^>]*>|redacted|example|placeholder|your[ _-]|insert|paste|truncated`)
+)
+
+// isPrivateKeyLabel reports whether a PEM label such as "RSA PRIVATE KEY",
+// "ENCRYPTED PRIVATE KEY" or "PGP PRIVATE KEY BLOCK" denotes secret material.
+func isPrivateKeyLabel(label string) bool {
+	return strings.Contains(label, "PRIVATE KEY")
+}
+
+// isPublicPEMLabel reports whether a PEM label denotes public material, such
+// as certificates and public keys, whose base64 bodies would otherwise trip
+// the line-level detectors.
+func isPublicPEMLabel(label string) bool {
+	return strings.Contains(label, "CERTIFICATE") || strings.Contains(label, "PUBLIC KEY") ||
+		label == "X509 CRL" || label == "DH PARAMETERS" || label == "EC PARAMETERS"
+}
+
+// pemBlock is a PEM block being assembled from consecutive lines.
+type pemBlock struct {
+	label     string
+	startLine int
+	// column is the 0-based offset of the BEGIN marker on the start line.
+	column int
+	// snippet is the start line as shown with the finding, without the key
+	// material of a block that ends on the same line.
+	snippet string
+	lines   []string
+	body    strings.Builder
+	inBody  bool
+}
+
+// pemTracker recognizes PEM blocks spanning several lines of a file.
+type pemTracker struct {
+	block *pemBlock
+}
+
+// pemEvent describes how a line relates to PEM blocks.
+type pemEvent struct {
+	// Covered reports the byte range of the line, as [from, to), that belongs
+	// to a block. Line-level matches inside it are superseded by the block.
+	Covered []int
+	// Finding, if non-nil, is a block that ended on this line.
+	Finding *Match
+	// Truncated, if non-nil, is a block that was cut short before this line,
+	// which is then processed afresh.
+	Truncated *Match
+}
+
+// feed advances the tracker by one line of path.
+func (pt *pemTracker) feed(path string, lineNo int, line string) pemEvent {
+	var ev pemEvent
+
+	if b := pt.block; b != nil {
+		if loc := pemEnd.FindStringSubmatchIndex(line); loc != nil && line[loc[2]:loc[3]] == b.label {
+			b.lines = append(b.lines, line[:loc[1]])
+			pt.block = nil
+			ev.Covered = []int{0, loc[1]}
+			ev.Finding = b.finish(path, lineNo, loc[1], false)
+			return ev
+		}
+		if len(b.lines) < pemMaxBodyLines && b.accept(line) {
+			b.lines = append(b.lines, line)
+			ev.Covered = []int{0, len(line)}
+			return ev
+		}
+		// The line is not key material: the block was cut short, and this
+		// line is scanned like any other.
+		pt.block = nil
+		ev.Truncated = b.finish(path, lineNo-1, len(b.lines[len(b.lines)-1]), true)
+	}
+
+	loc := pemBegin.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return ev
+	}
+	label := line[loc[2]:loc[3]]
+	if !isPrivateKeyLabel(label) && !isPublicPEMLabel(label) {
+		return ev
+	}
+
+	b := &pemBlock{label: label, startLine: lineNo, column: loc[0], snippet: strings.TrimSpace(line)}
+
+	// Keys embedded in JSON or code often sit on one line with escaped
+	// newlines between BEGIN and END.
+	rest := line[loc[1]:]
+	if end := pemEnd.FindStringSubmatchIndex(rest); end != nil && rest[end[2]:end[3]] == label {
+		for _, part := range strings.Split(strings.ReplaceAll(rest[:end[0]], `\n`, "\n"), "\n") {
+			b.accept(part)
+		}
+		b.lines = []string{line[loc[0] : loc[1]+end[1]]}
+		b.snippet = strings.TrimSpace(line[:loc[1]] + redactMask + rest[end[0]:end[1]])
+		ev.Covered = []int{loc[0], loc[1] + end[1]}
+		ev.Finding = b.finish(path, lineNo, loc[1]+end[1], false)
+		return ev
+	}
+
+	b.lines = []string{line[loc[0]:]}
+	pt.block = b
+	ev.Covered = []int{loc[0], len(line)}
+	return ev
+}
+
+// flush ends any block still open at end of input, returning it as truncated.
+func (pt *pemTracker) flush(path string, lastLine int) *Match {
+	b := pt.block
+	if b == nil {
+		return nil
+	}
+	pt.block = nil
+	return b.finish(path, lastLine, len(b.lines[len(b.lines)-1]), true)
+}
+
+// accept adds line to the block body if it looks like part of a PEM body,
+// reporting whether it did.
+func (b *pemBlock) accept(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "" || trimmed == `\n`:
+		return true
+	case !b.inBody && pemHeaderLine.MatchString(line):
+		return true
+	case pgpChecksumLine.MatchString(line):
+		return true
+	}
+	if m := pemBodyLine.FindStringSubmatch(line); m != nil {
+		b.inBody = b.inBody || m[1] != ""
+		b.body.WriteString(m[1])
+		return true
+	}
+	if pemPlaceholder.MatchString(line) {
+		// Keep illustrative bodies together so they are classified as a
+		// placeholder rather than split into stray lines.
+		b.body.WriteString(trimmed)
+		return true
+	}
+	return false
+}
+
+// finish turns a completed or truncated block into a finding. Public blocks
+// produce none; private keys are classified as real key material or as a
+// placeholder or truncated example. A block ending on its start line has no
+// EndLine.
+func (b *pemBlock) finish(path string, endLine, endColumn int, truncated bool) *Match {
+	if !isPrivateKeyLabel(b.label) {
+		return nil
+	}
+
+	body := b.body.String()
+	rule := &privateKeyBlockRule
+	if truncated || !isRealKeyMaterial(body) {
+		rule = &privateKeyExampleRule
+	}
+
+	if endLine == b.startLine {
+		endLine = 0
+	}
+	return &Match{
+		File:        path,
+		Line:        b.startLine,
+		EndLine:     endLine,
+		LineSnippet: b.snippet,
+		MatchText:   strings.Join(b.lines, "\n"),
+		Column:      b.column + 1,
+		EndColumn:   endColumn + 1,
+		RuleID:      rule.ID,
+		Severity:    rule.Severity,
+		Entropy:     roundEntropy(shannonEntropy(body)),
+	}
+}
+
+// isRealKeyMaterial reports whether a PEM body decodes to a plausible amount
+// of key material rather than being a placeholder.
+func isRealKeyMaterial(body string) bool {
+	if body == "" || pemPlaceholder.MatchString(body) {
+		return false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(body, "="))
+		if err != nil {
+			return false
+		}
+	}
+	return len(decoded) >= pemMinKeyBytes
+}
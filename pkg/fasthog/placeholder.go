@@ -0,0 +1,171 @@
+package fasthog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Reasons a matched value is recognized as a placeholder rather than a
+// secret, as returned by placeholderReason.
+const (
+	reasonEmpty         = "empty"
+	reasonShell         = "shell-variable"
+	reasonTerraform     = "terraform-reference"
+	reasonHelm          = "helm-template"
+	reasonGitHubActions = "github-actions-expression"
+	reasonJinja         = "jinja-template"
+	reasonSpring        = "spring-property"
+	reasonFunctionCall  = "function-call"
+	reasonPlaceholder   = "placeholder-word"
+)
+
+// Placeholder records a strict pattern match that was not reported because
+// its value is a placeholder.
+type Placeholder struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	RuleID string `json:"rule_id"`
+	Reason string `json:"reason"`
+}
+
+func (p Placeholder) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", p.File, p.Line, p.RuleID, p.Reason)
+}
+
+// emptyValues are values, compared case-insensitively, that hold nothing.
+var emptyValues = map[string]bool{
+	"": true, "none": true, "null": true, "nil": true, "undefined": true, "~": true,
+	`""`: true, "''": true,
+}
+
+// placeholderWords are values, compared case-insensitively, that stand in for
+// a secret to be filled in.
+var placeholderWords = map[string]bool{
+	"changeme": true, "change_me": true, "change-me": true, "changeit": true,
+	"replaceme": true, "replace_me": true, "replace-me": true, "placeholder": true,
+	"example": true, "dummy": true, "redacted": true, "todo": true, "tbd": true,
+}
+
+var (
+	// githubExpression matches ${{ secrets.X }} and other expressions.
+	githubExpression = regexp.MustCompile(`^\$\{\{.*\}\}$`)
+	// helmTemplate matches {{ .Values.x }}, {{- include ... }} and the
+	// like: actions starting with a field or a Helm function.
+	helmTemplate = regexp.MustCompile(`^\{\{-?\s*(\.|\$\.|(include|required|tpl|default|template|lookup)\b).*\}\}$`)
+	// jinjaTemplate matches {{ var }} expressions and {% %} statements.
+	jinjaTemplate = regexp.MustCompile(`^(\{\{.*\}\}|\{%.*%\})$`)
+	// terraformReference matches var.x, local.x, data.x.y and module.x.y,
+	// optionally interpolated.
+	terraformReference = regexp.MustCompile(`^(\$\{)?(var|local|data|module)\.[\w.\["\]-]+\}?$`)
+	// springProperty matches ${dotted.property}, optionally with a
+	// ":default", and #{...} SpEL expressions.
+	springProperty = regexp.MustCompile(`^(\$\{[a-z][\w-]*(\.[\w-]+)+(:[^}]*)?\}|#\{.*\})$`)
+	// shellVariable matches $VAR, ${VAR}, ${VAR:-default}, $(command) and
+	// %VAR%.
+	shellVariable = regexp.MustCompile(`^(\$[A-Za-z_]\w*|\$\{[^}]+\}|\$\(.*\)|%[A-Za-z_]\w*%)$`)
+	// functionCall matches calls and lookups such as getpass.getpass(...),
+	// os.environ["X"] and process.env.X.
+	functionCall = regexp.MustCompile(`^([A-Za-z_][\w.]*(\(.*\)|\[.*\])|process\.env\.\w+)$`)
+	// maskedValue matches values of repeated filler characters, such as
+	// xxxx or ****.
+	maskedValue = regexp.MustCompile(`^(x{3,}|X{3,}|\*{3,}|\.{3,}|#{3,}|0{3,})$`)
+	// fillInValue matches angle-bracketed and "your-...-here" values.
+	fillInValue = regexp.MustCompile(`(?i)^(<[^<>]+>|(your|enter|insert)[-_ ][\w -]+|[\w-]+[-_ ]here)$`)
+)
+
+// placeholderReason classifies value, the unquoted value assigned in a
+// match, and returns why it is a placeholder, or "" if it may be a secret.
+func placeholderReason(value string) string {
+	v := strings.TrimSpace(value)
+	switch {
+	case emptyValues[strings.ToLower(v)]:
+		return reasonEmpty
+	case githubExpression.MatchString(v):
+		return reasonGitHubActions
+	case helmTemplate.MatchString(v):
+		return reasonHelm
+	case jinjaTemplate.MatchString(v):
+		return reasonJinja
+	case terraformReference.MatchString(v):
+		return reasonTerraform
+	case springProperty.MatchString(v):
+		return reasonSpring
+	case shellVariable.MatchString(v):
+		return reasonShell
+	case functionCall.MatchString(v):
+		return reasonFunctionCall
+	case placeholderWords[strings.ToLower(v)] || maskedValue.MatchString(v) || fillInValue.MatchString(v):
+		return reasonPlaceholder
+	}
+	return ""
+}
+
+// assignedValue returns the value assigned in the match at loc in line: the
+// expression after the first '=' or ':' in the match, which may extend past
+// the match, without its quotes. ok is false if the match has no separator.
+func assignedValue(line string, loc []int) (value string, ok bool) {
+	sep := strings.IndexAny(line[loc[0]:loc[1]], "=:")
+	if sep < 0 {
+		return "", false
+	}
+	start := loc[0] + sep
+	for start < len(line) && strings.IndexByte("=: \t", line[start]) >= 0 {
+		start++
+	}
+	if start == len(line) {
+		return "", true
+	}
+	if q := line[start]; q == '"' || q == '\'' || q == '`' {
+		end := strings.IndexByte(line[start+1:], q)
+		if end < 0 {
+			return line[start+1:], true
+		}
+		return line[start+1 : start+1+end], true
+	}
+	// An unquoted value ends at whitespace or punctuation outside brackets.
+	depth := 0
+	for i := start; i < len(line); i++ {
+		switch line[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return line[start:i], true
+			}
+			depth--
+		case ' ', '\t', ',', ';':
+			if depth == 0 {
+				return line[start:i], true
+			}
+		}
+	}
+	return line[start:], true
+}
+
+// dropPlaceholders drops the strict pattern matches on chunk whose assigned
+// value is a placeholder, recording each with its reason. Provider token
+// matches are the token itself and are always kept.
+func (ls *lineScanner) dropPlaceholders(chunk lineChunk, matches []Match) []Match {
+	return slices.DeleteFunc(matches, func(m Match) bool {
+		rule, ok := ls.opts.SlowPatterns.Lookup(m.RuleID)
+		if !ok || rule.Provider != "" || m.Encoded != "" {
+			return false
+		}
+		value, ok := assignedValue(chunk.Text, []int{m.Column - 1 - chunk.Offset, m.EndColumn - 1 - chunk.Offset})
+		if !ok {
+			return false
+		}
+		reason := placeholderReason(value)
+		if reason != "" {
+			ls.notePlaceholder(m, reason)
+		}
+		return reason != ""
+	})
+}
+
+// notePlaceholder records that m was not reported for reason.
+func (ls *lineScanner) notePlaceholder(m Match, reason string) {
+	ls.res.Placeholders = append(ls.res.Placeholders, Placeholder{File: ls.path, Line: m.Line, RuleID: m.RuleID, Reason: reason})
+}
@@ -0,0 +1,100 @@
+package fasthog
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPlaceholderReason(t *testing.T) {
+	tests := map[string]string{
+		"":                                reasonEmpty,
+		"None":                            reasonEmpty,
+		"null":                            reasonEmpty,
+		`""`:                              reasonEmpty,
+		"$ADMIN_PASSWORD":                 reasonShell,
+		"${ADMIN_PASSWORD}":               reasonShell,
+		"${DB_PASSWORD:-postgres}":        reasonShell,
+		"$(cat /run/secrets/db)":          reasonShell,
+		"%API_KEY%":                       reasonShell,
+		"var.db_password":                 reasonTerraform,
+		"${var.db_password}":              reasonTerraform,
+		`data.vault_generic_secret.db`:    reasonTerraform,
+		"{{ .Values.db.password }}":       reasonHelm,
+		`{{- include "chart.secret" . }}`: reasonHelm,
+		"${{ secrets.REPO_PASSWORD }}":    reasonGitHubActions,
+		"{{ db_password }}":               reasonJinja,
+		"{% raw %}":                       reasonJinja,
+		"${spring.datasource.password}":   reasonSpring,
+		"${db.password:secret}":           reasonSpring,
+		"#{systemProperties['pw']}":       reasonSpring,
+		`getpass.getpass("Password: ")`:   reasonFunctionCall,
+		`os.environ["API_KEY"]`:           reasonFunctionCall,
+		"process.env.API_KEY":             reasonFunctionCall,
+		"changeme":                        reasonPlaceholder,
+		"REPLACE_ME":                      reasonPlaceholder,
+		"xxxxxxxx":                        reasonPlaceholder,
+		"********":                        reasonPlaceholder,
+		"<your-key-here>":                 reasonPlaceholder,
+		"your_api_key":                    reasonPlaceholder,
+		"token-goes-here":                 reasonPlaceholder,
+		"thisisaplaintextpassword":        "",
+		"3^thisisaplaintextpassword~&P?3": "",
+		"$3cr3t!":                         "",
+		"xkcd-correct-horse":              "",
+	}
+	for value, want := range tests {
+		if got := placeholderReason(value); got != want {
+			t.Errorf("placeholderReason(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestAssignedValue(t *testing.T) {
+	tests := []struct {
+		line  string
+		match string
+		want  string
+		ok    bool
+	}{
+		{`password = "changeme"`, `password = "changeme"`, "changeme", true},
+		{`pwd = getpass.getpass("Grafana password: ")`, `pwd = getpass`, `getpass.getpass("Grafana password: ")`, true},
+		{`sasl_plain_password: str = None):`, `sasl_plain_password: str`, "str", true},
+		{`token: ${{ secrets.TOKEN }} # ci`, `token: ${{`, "${{ secrets.TOKEN }}", true},
+		{`api_key=`, `api_key=`, "", true},
+		{`Authorization Bearer abc`, `Bearer abc`, "", false},
+	}
+	for _, tt := range tests {
+		start := strings.Index(tt.line, tt.match)
+		got, ok := assignedValue(tt.line, []int{start, start + len(tt.match)})
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("assignedValue(%q, %q) = %q, %v, want %q, %v", tt.line, tt.match, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// TestDropPlaceholdersCorpus verifies that the classifier keeps every finding
+// in the positives corpus when exclude patterns are out of the way.
+func TestDropPlaceholdersCorpus(t *testing.T) {
+	ps := testPatterns(t)
+	noExclude := regexp.MustCompile(`[^\x00-\x{10FFFF}]`)
+	opts := scanOptions{ExcludePatterns: noExclude, FastPatterns: ps.Fast, SlowPatterns: ps.Slow}
+
+	b, err := os.ReadFile("../../test/Positives.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		res := scanReader(opts, "corpus.txt", strings.NewReader(line))
+		if len(res.Placeholders) != 0 {
+			t.Errorf("%q treated as a placeholder: %+v", line, res.Placeholders)
+		}
+	}
+
+	// [FP05] from test/False_Positives.txt.
+	res := scanReader(opts, "grafana.py", strings.NewReader(`pwd = getpass.getpass("Grafana password: ")`))
+	if len(res.Matches) != 0 {
+		t.Errorf("expected no findings for a function call, got %+v", res.Matches)
+	}
+}
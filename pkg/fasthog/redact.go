@@ -0,0 +1,182 @@
+package fasthog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// redactMask replaces the hidden part of a secret. Its length is fixed so
+// that it does not reveal the length of the secret.
+const redactMask = "*****"
+
+// redactMaxKeep is the most bytes of a secret kept visible at either end.
+const redactMaxKeep = 3
+
+// secretValueCandidate matches a value following an assignment operator or a
+// quote; the longest such value in a match is taken as the secret itself, as
+// opposed to the variable name or quoting around it.
+var secretValueCandidate = regexp.MustCompile("(?:[:=]>?\\s*[\"'`]?|[\"'`])([^\\s\"'`]+)")
+
+// secretValue returns the secret within a match's text: its longest assigned
+// or quoted value, or the whole text if it has none, such as a bare token.
+func secretValue(matchText string) string {
+	value := ""
+	for _, sub := range secretValueCandidate.FindAllStringSubmatchIndex(matchText, -1) {
+		if sub[3]-sub[2] > len(value) {
+			value = matchText[sub[2]:sub[3]]
+		}
+	}
+	if value == "" {
+		return matchText
+	}
+	return value
+}
+
+// assignmentAfter matches the assignment operator following a key name,
+// after the name's closing quote, if any.
+var assignmentAfter = regexp.MustCompile("^[\"'`]?\\s*:?[:=]")
+
+// valueAfter returns the value assigned right after the match at loc in
+// line when the match is a key name alone, such as AWS_SECRET_ACCESS_KEY in
+// AWS_SECRET_ACCESS_KEY="wJalr...". It returns "" if the match holds its own
+// assignment or none follows.
+func valueAfter(line string, loc []int) string {
+	if _, ok := assignedValue(line, loc); ok {
+		return ""
+	}
+	op := assignmentAfter.FindStringIndex(line[loc[1]:])
+	if op == nil {
+		return ""
+	}
+	value, _ := assignedValue(line, []int{loc[1], loc[1] + op[1]})
+	return value
+}
+
+// matchSecret returns the secret of m: the value assigned after a matched
+// key name, the whole of a key block, or else the secret within its match
+// text.
+func matchSecret(m Match) string {
+	if isKeyBlock(m) {
+		return m.MatchText
+	}
+	if m.valueText != "" {
+		return m.valueText
+	}
+	return secretValue(m.MatchText)
+}
+
+// secretHash returns the SHA-256 of the secret of m, which correlates
+// occurrences of the same secret once their values are redacted.
+func secretHash(m Match) string {
+	sum := sha256.Sum256([]byte(matchSecret(m)))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskSecret hides all but a short prefix and suffix of value, keeping at
+// most a quarter of it visible.
+func maskSecret(value string) string {
+	keep := min(redactMaxKeep, len(value)/4)
+	return value[:keep] + redactMask + value[len(value)-keep:]
+}
+
+// isKeyBlock reports whether m is a private key block, whose match text is
+// the whole block, on one line or several.
+func isKeyBlock(m Match) bool {
+	return m.RuleID == privateKeyBlockRule.ID || m.RuleID == privateKeyExampleRule.ID
+}
+
+// redactKeyBlock keeps the BEGIN and END markers of a key block's text and
+// masks the body between them. Blocks on several lines keep their first and
+// last lines, which hold the markers.
+func redactKeyBlock(text string) string {
+	if lines := strings.Split(text, "\n"); len(lines) > 2 {
+		return lines[0] + "\n" + redactMask + "\n" + lines[len(lines)-1]
+	}
+	begin := pemBegin.FindStringIndex(text)
+	end := pemEnd.FindStringIndex(text)
+	if begin == nil || end == nil || end[0] < begin[1] {
+		return redactMask
+	}
+	return text[:begin[1]] + redactMask + text[end[0]:]
+}
+
+// RedactMatchText masks the secret within a match's text. Key blocks keep
+// their BEGIN and END markers and lose their body.
+func RedactMatchText(m Match) string {
+	if m.valueText != "" {
+		return m.MatchText // A key name; its value is masked in the snippet.
+	}
+	if isKeyBlock(m) {
+		return redactKeyBlock(m.MatchText)
+	}
+	value := secretValue(m.MatchText)
+	return strings.Replace(m.MatchText, value, maskSecret(value), 1)
+}
+
+// secretValues returns the secrets that m reveals in its line: the value
+// within its match text or assigned right after it, and the encoded form of
+// a decoded value.
+func secretValues(m Match) []string {
+	if m.IsFileFinding() || isKeyBlock(m) {
+		// File names are not secrets, and key blocks are masked whole by
+		// RedactText.
+		return nil
+	}
+	values := []string{matchSecret(m)}
+	if m.encodedText != "" {
+		values = append(values, m.encodedText)
+	}
+	return values
+}
+
+// RedactText masks every secret revealed by matches wherever it appears in
+// text, such as the line or snippet holding them. The body of a key block on
+// a single line is masked wherever the block appears.
+func RedactText(text string, matches []Match) string {
+	for _, m := range matches {
+		if isKeyBlock(m) && m.EndLine == 0 {
+			text = strings.ReplaceAll(text, m.MatchText, redactKeyBlock(m.MatchText))
+		}
+		for _, v := range secretValues(m) {
+			text = strings.ReplaceAll(text, v, maskSecret(v))
+		}
+	}
+	return text
+}
+
+// RedactMatches returns copies of matches with every secret masked in their
+// match text and snippets. A snippet is masked for all the findings on its
+// line, not just its own.
+func RedactMatches(matches []Match) []Match {
+	type lineKey struct {
+		file string
+		line int
+		sha  string
+	}
+	keyOf := func(m Match) lineKey {
+		k := lineKey{file: m.File, line: m.Line}
+		if m.Commit != nil {
+			k.sha = m.Commit.SHA
+		}
+		return k
+	}
+
+	byLine := make(map[lineKey][]Match)
+	for _, m := range matches {
+		byLine[keyOf(m)] = append(byLine[keyOf(m)], m)
+	}
+
+	out := make([]Match, len(matches))
+	for i, m := range matches {
+		if m.IsFileFinding() {
+			out[i] = m // The match text is the file name, not a secret.
+			continue
+		}
+		m.LineSnippet = RedactText(m.LineSnippet, byLine[keyOf(m)])
+		m.MatchText = RedactMatchText(m)
+		out[i] = m
+	}
+	return out
+}
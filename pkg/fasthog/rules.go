@@ -0,0 +1,323 @@
+package fasthog
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ruleAnnotationPrefix introduces a structured comment describing the pattern
+// on the next non-comment line of a .regex file, e.g.:
+//
+//	# @rule id=aws-access-key-id type=aws severity=critical description="AWS access key ID"
+//	AKIA[0-9A-Z]{16}
+const ruleAnnotationPrefix = "# @rule "
+
+// matchNothing is a regex that matches no input, compiled in place of an
+// empty set of patterns.
+const matchNothing = `[^\x00-\x{10FFFF}]`
+
+// defaultSecretType is used for patterns that do not declare a type.
+const defaultSecretType = "generic"
+
+// Rule is a single named detection pattern.
+type Rule struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description,omitempty"`
+	SecretType  string   `json:"secret_type"`
+	Severity    Severity `json:"severity"`
+	Pattern     string   `json:"pattern"`
+
+	// Provider names the service whose exact token format the rule matches.
+	// A provider rule's matches take precedence over overlapping matches of
+	// generic rules, such as the assignment the token is the value of.
+	Provider string `json:"provider,omitempty"`
+	// Checksum names the checksumValidators entry that a match must pass,
+	// rejecting look-alikes of the token format.
+	Checksum string `json:"checksum,omitempty"`
+	// Files is the scope of the pattern file section the rule is in, such
+	// as "*.tf, *.tfvars", or empty if it applies to every file.
+	Files string `json:"files,omitempty"`
+
+	// Source is the file and line the pattern was loaded from.
+	Source string `json:"source"`
+}
+
+// RuleSet is an ordered collection of rules compiled into a single
+// alternation, so that a line is still matched in one pass while each match
+// can be attributed to the rule that produced it.
+type RuleSet struct {
+	Rules []Rule
+
+	re *regexp.Regexp
+	// groups holds, for each rule, the index of the capture group wrapping it
+	// in re.
+	groups []int
+
+	// providers is the alternation of the provider rules alone, or nil if
+	// there are none; providerGroups and providerRules hold the capture
+	// group wrapping each of them and its index in Rules.
+	providers      *regexp.Regexp
+	providerGroups []int
+	providerRules  []int
+}
+
+// MatchString reports whether any rule matches s. Checksums are not
+// verified.
+func (rs *RuleSet) MatchString(s string) bool {
+	return rs.re.MatchString(s)
+}
+
+// ruleMatch is a single match and the rule that produced it.
+type ruleMatch struct {
+	Loc  []int
+	Rule *Rule
+}
+
+// findAllStringIndex returns every successive non-overlapping match in s,
+// each with the rule that produced it. Matches failing their rule's checksum
+// are dropped, and provider rule matches replace the generic matches they
+// overlap.
+func (rs *RuleSet) findAllStringIndex(s string) []ruleMatch {
+	matches := rs.findAll(rs.re, rs.groups, nil, s)
+	if rs.providers == nil {
+		return matches
+	}
+	tokens := rs.findAll(rs.providers, rs.providerGroups, rs.providerRules, s)
+	if len(tokens) == 0 {
+		return matches
+	}
+	matches = slices.DeleteFunc(matches, func(m ruleMatch) bool {
+		return slices.ContainsFunc(tokens, func(t ruleMatch) bool {
+			return m.Loc[0] < t.Loc[1] && t.Loc[0] < m.Loc[1]
+		})
+	})
+	matches = append(matches, tokens...)
+	slices.SortFunc(matches, func(a, b ruleMatch) int { return a.Loc[0] - b.Loc[0] })
+	return matches
+}
+
+// findAll returns the matches of re in s whose checksums pass. groups holds
+// the capture group wrapping each alternative of re, and index maps an
+// alternative to its rule in Rules; a nil index maps alternatives one to one.
+func (rs *RuleSet) findAll(re *regexp.Regexp, groups, index []int, s string) []ruleMatch {
+	var matches []ruleMatch
+	for _, sub := range re.FindAllStringSubmatchIndex(s, -1) {
+		rule := rs.ruleFor(sub, groups, index)
+		if rule != nil && rule.Checksum != "" && !checksumValidators[rule.Checksum](s[sub[0]:sub[1]]) {
+			continue
+		}
+		matches = append(matches, ruleMatch{Loc: sub[:2], Rule: rule})
+	}
+	return matches
+}
+
+// ruleFor returns the rule whose wrapping group participated in the
+// submatch sub.
+func (rs *RuleSet) ruleFor(sub []int, groups, index []int) *Rule {
+	for i, g := range groups {
+		if sub[2*g] >= 0 {
+			if index != nil {
+				i = index[i]
+			}
+			return &rs.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Lookup returns the rule with the given ID.
+func (rs *RuleSet) Lookup(id string) (*Rule, bool) {
+	for i := range rs.Rules {
+		if rs.Rules[i].ID == id {
+			return &rs.Rules[i], true
+		}
+	}
+	return nil, false
+}
+
+// readRules reads named rules from one or more .regex files. Each
+// non-comment line is one rule; an optional "# @rule" annotation on the
+// preceding line supplies its metadata. Unannotated patterns get an ID
+// derived from their file and line number. Rules after a section header are
+// scoped to its files.
+func readRules(filesystem fs.FS, paths ...string) ([]Rule, error) {
+	var rules []Rule
+	seen := make(map[string]string)
+
+	for _, p := range paths {
+		b, err := fs.ReadFile(filesystem, p)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load regexes from %s: %w", p, err)
+		}
+
+		var pending *Rule
+		scope := allFiles
+		for i, raw := range bytes.Split(b, []byte("\n")) {
+			line := strings.TrimSuffix(string(raw), "\r")
+			source := fmt.Sprintf("%s:%d", p, i+1)
+
+			if strings.HasPrefix(line, ruleAnnotationPrefix) {
+				annotated, err := parseRuleAnnotation(strings.TrimPrefix(line, ruleAnnotationPrefix))
+				if err != nil {
+					return nil, fmt.Errorf("invalid rule annotation at %s: %w", source, err)
+				}
+				pending = &annotated
+				continue
+			}
+			if len(line) == 0 || line[0] == '#' {
+				continue
+			}
+			if s, ok, err := parseSectionHeader(line); ok {
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", source, err)
+				}
+				scope = s
+				continue
+			}
+
+			rule := Rule{}
+			if pending != nil {
+				rule = *pending
+				pending = nil
+			}
+			if rule.ID == "" {
+				rule.ID = fmt.Sprintf("%s-%d", strings.TrimSuffix(path.Base(p), ".regex"), i+1)
+			}
+			if rule.SecretType == "" {
+				rule.SecretType = defaultSecretType
+			}
+			if rule.Severity == "" {
+				rule.Severity = defaultSeverity
+			}
+			rule.Pattern = shellReplacer.Replace(line)
+			rule.Files = scope
+			rule.Source = source
+
+			if prev, ok := seen[rule.ID]; ok {
+				return nil, fmt.Errorf("duplicate rule id %q at %s (first defined at %s)", rule.ID, source, prev)
+			}
+			seen[rule.ID] = source
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// compileRules combines rules into a RuleSet, recording which capture group
+// wraps each rule. Provider rules are also combined on their own.
+func compileRules(rules []Rule) (*RuleSet, error) {
+	rs := &RuleSet{Rules: rules}
+	for i, rule := range rules {
+		if rule.Checksum != "" && checksumValidators[rule.Checksum] == nil {
+			return nil, fmt.Errorf("rule %s (%s): unknown checksum %q", rule.ID, rule.Source, rule.Checksum)
+		}
+		if rule.Provider != "" {
+			rs.providerRules = append(rs.providerRules, i)
+		}
+	}
+
+	var err error
+	if rs.re, rs.groups, err = compileAlternation(rules); err != nil {
+		return nil, err
+	}
+	if len(rs.providerRules) > 0 {
+		providers := make([]Rule, len(rs.providerRules))
+		for i, r := range rs.providerRules {
+			providers[i] = rules[r]
+		}
+		if rs.providers, rs.providerGroups, err = compileAlternation(providers); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+// compileAlternation compiles rules into a single alternation and returns
+// the capture group wrapping each of them.
+func compileAlternation(rules []Rule) (*regexp.Regexp, []int, error) {
+	groups := make([]int, len(rules))
+
+	var combined strings.Builder
+	group := 1
+	for i, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to compile regex patterns: rule %s (%s): %w", rule.ID, rule.Source, err)
+		}
+		if i > 0 {
+			combined.WriteString("|")
+		}
+		combined.WriteString("(" + rule.Pattern + ")")
+		groups[i] = group
+		group += 1 + re.NumSubexp()
+	}
+
+	if len(rules) == 0 {
+		// An empty alternation would match every line; an empty rule set
+		// must match none.
+		combined.WriteString(matchNothing)
+	}
+
+	re, err := regexp.Compile(combined.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compile regex patterns: %w", err)
+	}
+	return re, groups, nil
+}
+
+// parseRuleAnnotation parses the key=value pairs of a "# @rule" comment.
+// Values containing spaces must be double-quoted.
+func parseRuleAnnotation(s string) (Rule, error) {
+	var rule Rule
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return Rule{}, fmt.Errorf("expected key=value, got %q", s)
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return Rule{}, fmt.Errorf("unterminated quoted value for %s", key)
+			}
+			value, s = s[1:end+1], s[end+2:]
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = s[:end], s[end:]
+		}
+
+		switch key {
+		case "id":
+			rule.ID = value
+		case "description":
+			rule.Description = value
+		case "type":
+			rule.SecretType = value
+		case "provider":
+			rule.Provider = value
+		case "checksum":
+			rule.Checksum = value
+		case "severity":
+			sev, err := ParseSeverity(value)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Severity = sev
+		default:
+			return Rule{}, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	return rule, nil
+}
@@ -0,0 +1,908 @@
+package fasthog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultExcludeDirs contains directory names to skip during scanning.
+var DefaultExcludeDirs = []string{
+	".git", ".github", "node_modules", "vendor", ".idea", ".vscode",
+}
+
+// DefaultExtensions contains file extensions to scan when no specific types are provided.
+var DefaultExtensions = []string{
+	".js", ".json", ".py", ".cs", ".go", ".java", ".sh", ".tf", ".yml", ".yaml",
+	".env", "env", ".ENV", "ENV", ".key", ".backup", ".tfstate", ".ts", ".txt",
+	".md", ".properties",
+}
+
+// Severity ranks how serious a finding is.
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// defaultSeverity is assigned to findings whose pattern does not declare one.
+const defaultSeverity = SeverityHigh
+
+// SeverityRank orders severities from least to most serious. Unknown values rank zero.
+func SeverityRank(s Severity) int {
+	switch s {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity converts a user-supplied string into a Severity value.
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	if SeverityRank(sev) == 0 {
+		return "", fmt.Errorf("invalid severity %q (supported: low, medium, high, critical)", s)
+	}
+	return sev, nil
+}
+
+// Match represents a single detected secret occurrence.
+type Match struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// EndLine is set for findings spanning several lines, such as private
+	// key blocks.
+	EndLine     int    `json:"end_line,omitempty"`
+	LineSnippet string `json:"line_snippet"`
+	MatchText   string `json:"match_text"`
+	// Column and EndColumn are 1-based byte offsets of the match within the
+	// line; EndColumn is one past the last byte. For multi-line findings,
+	// EndColumn refers to EndLine.
+	Column    int      `json:"column"`
+	EndColumn int      `json:"end_column"`
+	RuleID    string   `json:"rule_id"`
+	Severity  Severity `json:"severity"`
+	// Entropy is the Shannon entropy of MatchText in bits per byte.
+	Entropy float64 `json:"entropy"`
+	// Commit identifies the commit that added the match in --git-history
+	// mode.
+	Commit *CommitInfo `json:"commit,omitempty"`
+	// Fingerprint identifies the finding in --baseline files independently
+	// of its line and column.
+	Fingerprint string `json:"fingerprint"`
+	// SecretHash is the SHA-256 of the secret value, which correlates
+	// findings of the same secret when values are redacted.
+	SecretHash string `json:"secret_hash"`
+	// Encoded names the encoding, base64 or hex, of a finding in a decoded
+	// value. Column and EndColumn then locate the encoded value, while
+	// MatchText is decoded.
+	Encoded string `json:"encoded,omitempty"`
+	// encodedText is the encoded value, masked in snippets by redaction.
+	encodedText string
+	// valueText is the value assigned right after a match of a key name
+	// alone, such as AWS_SECRET_ACCESS_KEY, masked by redaction.
+	valueText string
+}
+
+// ScanError records a per-file failure that was skipped rather than aborting
+// the scan. Op names the failed operation: "walk", "open", "read" or "close".
+type ScanError struct {
+	File string
+	Op   string
+	Err  error
+}
+
+func (e ScanError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.File, e.Err)
+}
+
+func (e ScanError) Unwrap() error { return e.Err }
+
+// MarshalJSON renders the wrapped error as its message, since error values do
+// not serialize on their own.
+func (e ScanError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File  string `json:"file"`
+		Op    string `json:"op"`
+		Error string `json:"error"`
+	}{e.File, e.Op, e.Err.Error()})
+}
+
+// ScanWarning records a condition that made a file's scan less complete or
+// precise than usual without preventing it, such as very long lines.
+type ScanWarning struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (w ScanWarning) String() string {
+	return fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Message)
+}
+
+// scanOptions controls the behaviour of the core scanning engine.
+type scanOptions struct {
+	Directory   string
+	Extensions  []string
+	ExcludeDirs []string
+
+	// Filenames are base-name globs of files scanned whatever their
+	// extension, such as Dockerfile.
+	Filenames []string
+	// SniffExtensionless scans extensionless files matched by neither
+	// Extensions nor Filenames if their first bytes look like text.
+	SniffExtensionless bool
+
+	// BinaryStrings scans the printable strings of binary files, which are
+	// otherwise skipped.
+	BinaryStrings bool
+
+	// Decode rescans base64 and hex values that decode to printable text.
+	Decode bool
+
+	// Archives controls the scanning of archive members.
+	Archives ArchiveOptions
+
+	ExcludePatterns *regexp.Regexp
+	FastPatterns    *regexp.Regexp
+	SlowPatterns    *RuleSet
+
+	// Sections, if non-nil, replaces ExcludePatterns, FastPatterns and
+	// SlowPatterns for each file with the patterns that apply to it,
+	// including those of the pattern file sections scoped to it.
+	Sections *Patterns
+
+	// Gitignore additionally excludes the paths matched by .gitignore files.
+	// The .fasthogignore file at the scan root is always honored.
+	Gitignore bool
+
+	// JoinDirectory attributes findings in scanDirectory to paths joined
+	// with Directory rather than relative to it, for scans of several
+	// targets.
+	JoinDirectory bool
+
+	// Entropy configures the entropy detector that runs alongside the regex
+	// stages.
+	Entropy EntropyOptions
+
+	// Baseline, if non-nil, holds the fingerprints of accepted findings,
+	// which are dropped from the results.
+	Baseline Baseline
+
+	// MaxLineLength bounds how many bytes of a single line are matched at once.
+	// Longer lines are scanned in overlapping windows of this size. Zero means
+	// DefaultMaxLineLength.
+	MaxLineLength int
+
+	// OnCurrentFile, if non-nil, is invoked whenever a file is about to be scanned.
+	// index is zero-based, total is the total number of files to scan.
+	OnCurrentFile func(path string, index, total int)
+
+	// OnMatch, if non-nil, is invoked once for every line with at least one match.
+	OnMatch func(lm LineMatches)
+
+	// Context, if non-nil, stops the scan early once it is done. Files
+	// already being scanned are finished.
+	Context context.Context
+}
+
+// contextErr returns the error of opts.Context, or nil if it is unset or
+// not yet done.
+func (opts scanOptions) contextErr() error {
+	if opts.Context == nil {
+		return nil
+	}
+	return opts.Context.Err()
+}
+
+// canceled reports whether opts.Context is done.
+func (opts scanOptions) canceled() bool {
+	return opts.contextErr() != nil
+}
+
+// LineMatches groups the matches found on a single line, as passed to OnMatch.
+type LineMatches struct {
+	Path string
+	// Line is 0 for a file reported by name, which has a single match.
+	Line int
+	// Text is the line, or for lines scanned in windows an excerpt around the
+	// matches. For a file reported by name it is the rule's description.
+	Text string
+	// Offset is the byte offset of Text within the full line, so that a
+	// match spans Text[m.Column-1-Offset : m.EndColumn-1-Offset].
+	Offset  int
+	Matches []Match
+}
+
+// Result captures the structured output from a scan.
+type Result struct {
+	Matches    []Match
+	MatchFiles map[string]int
+	Filenames  []string
+	Errors     []ScanError
+	Warnings   []ScanWarning
+	// Suppressed counts findings dropped because they are in the baseline.
+	Suppressed int
+	// Ignored counts findings dropped by inline fasthog:ignore directives.
+	Ignored int
+	// Skipped counts binary files that were not scanned.
+	Skipped int
+	// Placeholders records the matches dropped because their value is a
+	// placeholder.
+	Placeholders []Placeholder
+}
+
+// DefaultMaxLineLength matches bufio.Scanner's default token limit, which
+// comfortably covers ordinary source lines.
+const DefaultMaxLineLength = bufio.MaxScanTokenSize
+
+// MinMaxLineLength is the smallest accepted --max-line-length; each window
+// must hold at least the overlap plus as many new bytes.
+const MinMaxLineLength = 2 * lineWindowOverlap
+
+// lineWindowOverlap is how many trailing bytes of one window of a long line are
+// repeated at the start of the next, so secrets straddling a window boundary
+// are still matched. It must exceed the longest match any pattern can produce.
+const lineWindowOverlap = 512
+
+// snippetContext is how many bytes either side of a match are kept as the
+// snippet for lines that had to be scanned in windows.
+const snippetContext = 80
+
+// lineReader yields the lines of a file. Lines longer than its window are
+// returned as a sequence of overlapping windows sharing one line number, so
+// that arbitrarily long lines (e.g. minified bundles) are scanned in bounded
+// memory instead of aborting the file.
+type lineReader struct {
+	r      *bufio.Reader
+	lineNo int
+
+	// carry holds the tail of the previous window when a line is continued.
+	carry []byte
+	// offset is the position of the next window within the current line.
+	offset int
+	// inLine reports whether the previous call returned a partial line.
+	inLine bool
+}
+
+func newLineReader(r io.Reader, window int) *lineReader {
+	if window <= 0 {
+		window = DefaultMaxLineLength
+	}
+	// The buffer holds the new bytes of a window; the carried overlap is added on top.
+	return &lineReader{r: bufio.NewReaderSize(r, max(window-lineWindowOverlap, lineWindowOverlap))}
+}
+
+// lineChunk is a line, or one window of a long line, returned by lineReader.
+type lineChunk struct {
+	Text   string
+	LineNo int
+	// Offset is the byte offset of Text within the full line.
+	Offset int
+	// Skip is the number of leading bytes of Text already returned as the
+	// tail of the previous window.
+	Skip int
+	// Windowed reports whether the line is being returned in windows.
+	Windowed bool
+}
+
+// next returns the next line, or window of a long line. It returns io.EOF
+// once the input is exhausted.
+func (lr *lineReader) next() (lineChunk, error) {
+	data, err := lr.r.ReadSlice('\n')
+	if err != nil && err != bufio.ErrBufferFull && err != io.EOF {
+		return lineChunk{}, err
+	}
+	if err == io.EOF && len(data) == 0 && !lr.inLine {
+		return lineChunk{}, io.EOF
+	}
+
+	if !lr.inLine {
+		lr.lineNo++
+		lr.offset = 0
+	}
+	chunk := lineChunk{
+		LineNo:   lr.lineNo,
+		Offset:   lr.offset,
+		Skip:     len(lr.carry),
+		Windowed: lr.inLine || err == bufio.ErrBufferFull,
+	}
+
+	text := append(lr.carry, data...)
+	if err == bufio.ErrBufferFull {
+		lr.inLine = true
+		keep := min(len(text), lineWindowOverlap)
+		lr.carry = append([]byte(nil), text[len(text)-keep:]...)
+		lr.offset += len(text) - keep
+	} else {
+		lr.inLine = false
+		lr.carry = nil
+		text = bytes.TrimSuffix(text, []byte("\n"))
+		text = bytes.TrimSuffix(text, []byte("\r"))
+	}
+	chunk.Text = string(text)
+	return chunk, nil
+}
+
+// snippetAround returns the match located at loc within line with up to
+// snippetContext bytes of surrounding context.
+func snippetAround(line string, loc []int) string {
+	return strings.TrimSpace(line[max(0, loc[0]-snippetContext):min(len(line), loc[1]+snippetContext)])
+}
+
+// mergeExcludeDirs returns the union of DefaultExcludeDirs and any additional
+// directories supplied in extra, preserving order and avoiding duplicates.
+func mergeExcludeDirs(extra []string) []string {
+	if len(extra) == 0 {
+		out := make([]string, len(DefaultExcludeDirs))
+		copy(out, DefaultExcludeDirs)
+		return out
+	}
+
+	seen := make(map[string]struct{}, len(DefaultExcludeDirs)+len(extra))
+	var merged []string
+
+	for _, d := range DefaultExcludeDirs {
+		if _, ok := seen[d]; !ok {
+			seen[d] = struct{}{}
+			merged = append(merged, d)
+		}
+	}
+
+	for _, d := range extra {
+		if _, ok := seen[d]; !ok {
+			seen[d] = struct{}{}
+			merged = append(merged, d)
+		}
+	}
+
+	return merged
+}
+
+// includePath reports whether the slash-separated path has one of the
+// extensions or matches one of the filename globs, and lies outside every
+// excluded directory.
+func includePath(path string, extensions, filenames, excludeDirs []string) bool {
+	if !hasExtension(path, extensions) && !matchesFilename(path, filenames) {
+		return false
+	}
+	return !inExcludedDir(path, excludeDirs)
+}
+
+// inExcludedDir reports whether any directory of the slash-separated path is
+// one of excludeDirs.
+func inExcludedDir(path string, excludeDirs []string) bool {
+	parts := strings.Split(path, "/")
+	return slices.ContainsFunc(excludeDirs, func(excludeDir string) bool {
+		return slices.Contains(parts, excludeDir)
+	})
+}
+
+// hasExtension checks if a file path has one of the specified extensions.
+func hasExtension(path string, extensions []string) bool {
+	return slices.ContainsFunc(extensions, func(ext string) bool {
+		return strings.HasSuffix(strings.ToLower(path), ext)
+	})
+}
+
+// scanDirectory walks the target directory and applies the supplied patterns,
+// returning structured matches and per-file counts. This function is intentionally
+// UI-agnostic so it can be reused by both the TUI and JSON output paths.
+func scanDirectory(opts scanOptions) Result {
+	root := os.DirFS(opts.Directory)
+	open := func(path string) (io.ReadCloser, error) {
+		return root.Open(path) // https://go.dev/blog/loopvar-preview
+	}
+	name := func(path string) string { return path }
+	if opts.JoinDirectory {
+		open = func(path string) (io.ReadCloser, error) {
+			return os.Open(filepath.FromSlash(path))
+		}
+		name = func(path string) string {
+			return filepath.ToSlash(filepath.Join(opts.Directory, path))
+		}
+	}
+	return scanFS(opts, root, name, open)
+}
+
+// scanFS walks root as scanDirectory does. name maps a path within root to
+// the path its findings are attributed to, and open opens a file by that
+// name.
+func scanFS(opts scanOptions, root fs.FS, name func(path string) string, open func(path string) (io.ReadCloser, error)) Result {
+	result := Result{
+		MatchFiles: make(map[string]int),
+	}
+	excludeDirs := mergeExcludeDirs(opts.ExcludeDirs)
+
+	ignorer, err := newPathIgnorer(root, opts.Gitignore)
+	if err != nil {
+		result.Errors = append(result.Errors, ScanError{File: name(fasthogIgnoreFile), Op: "read", Err: err})
+	}
+
+	// Collect the list of files to scan, and of files that are findings by
+	// name.
+	var filenames, sensitive []string
+	_ = fs.WalkDir(root, ".", func(path string, d fs.DirEntry, err error) error {
+		if opts.canceled() {
+			return fs.SkipAll
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, ScanError{File: name(path), Op: "walk", Err: err})
+			return nil
+		}
+		if d.IsDir() {
+			if path != "." && (slices.Contains(excludeDirs, d.Name()) || ignorer.ignored(path, true)) {
+				return fs.SkipDir
+			}
+			if err := ignorer.enterDir(path); err != nil {
+				ignoreFile := gitIgnoreFile
+				if path != "." {
+					ignoreFile = path + "/" + gitIgnoreFile
+				}
+				result.Errors = append(result.Errors, ScanError{File: name(ignoreFile), Op: "read", Err: err})
+			}
+			return nil
+		}
+		if ignorer.ignored(path, false) {
+			return nil
+		}
+		// Sensitive files are reported whatever their extension.
+		if matchSensitiveFile(path) != nil {
+			sensitive = append(sensitive, name(path))
+		}
+		if !includePath(path, opts.Extensions, opts.Filenames, excludeDirs) && !opts.Archives.includes(path) {
+			if !opts.SniffExtensionless || !isExtensionless(path) {
+				return nil
+			}
+			text, err := sniffText(name(path), open)
+			if err != nil {
+				result.Errors = append(result.Errors, ScanError{File: name(path), Op: "read", Err: err})
+				return nil
+			}
+			if !text {
+				return nil
+			}
+		}
+
+		filenames = append(filenames, name(path))
+		return nil
+	})
+
+	result.Filenames = filenames
+	scanFiles(opts, &result, filenames, open)
+	scanSensitiveFiles(opts, &result, sensitive, open)
+	return result
+}
+
+// scanFiles scans filenames concurrently, opening each with open, and adds
+// the findings, warnings and errors to result.
+func scanFiles(opts scanOptions, result *Result, filenames []string, open func(path string) (io.ReadCloser, error)) {
+	var (
+		mu        sync.Mutex
+		semaphore = make(chan struct{}, runtime.NumCPU())
+		wg        sync.WaitGroup
+	)
+
+	for i, path := range filenames {
+		if opts.canceled() {
+			break
+		}
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		if opts.OnCurrentFile != nil {
+			opts.OnCurrentFile(path, i, len(filenames))
+		}
+
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			recordError := func(op string, err error) {
+				mu.Lock()
+				result.Errors = append(result.Errors, ScanError{File: path, Op: op, Err: err})
+				mu.Unlock()
+			}
+
+			f, err := open(path)
+			if err != nil {
+				recordError("open", err)
+				return
+			}
+			defer func() {
+				if closeErr := f.Close(); closeErr != nil {
+					recordError("close", closeErr)
+				}
+			}()
+
+			fileRes := scanFile(opts, path, f)
+			mu.Lock()
+			mergeScanResult(result, fileRes)
+			mu.Unlock()
+		}(path)
+	}
+
+	wg.Wait()
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].File < result.Errors[j].File
+	})
+	sort.SliceStable(result.Warnings, func(i, j int) bool {
+		return result.Warnings[i].File < result.Warnings[j].File
+	})
+}
+
+// scanFile scans the file path read from r, or the members of an archive,
+// into a result of its own.
+func scanFile(opts scanOptions, path string, r io.Reader) Result {
+	if kind := archiveKind(path); kind != "" && opts.Archives.MaxDepth > 0 {
+		return scanArchive(opts, path, kind, r)
+	}
+	result := Result{MatchFiles: make(map[string]int)}
+	fileRes := scanContent(opts, path, r)
+	if fileRes.Err != nil {
+		result.Errors = append(result.Errors, ScanError{File: path, Op: "read", Err: fileRes.Err})
+	}
+	addFileScan(&result, path, fileRes)
+	return result
+}
+
+// fileScan is the outcome of scanning the contents of a single file.
+type fileScan struct {
+	Matches  []Match
+	Warnings []ScanWarning
+	// Suppressed counts findings dropped because they are in the baseline.
+	Suppressed int
+	// Ignored counts findings dropped by inline fasthog:ignore directives.
+	Ignored int
+	// Skipped is set for a binary file that was not scanned.
+	Skipped bool
+	// Placeholders records the matches dropped because their value is a
+	// placeholder.
+	Placeholders []Placeholder
+	// Err is the read error that ended the scan early, if any.
+	Err error
+}
+
+// scanContent scans the file read from r like scanReader, unless it is
+// binary, in which case it is skipped or, with BinaryStrings, only its
+// printable strings are scanned.
+func scanContent(opts scanOptions, path string, r io.Reader) fileScan {
+	br, binary, err := sniffBinary(r)
+	if err != nil {
+		return fileScan{Err: err}
+	}
+	if !binary {
+		return scanReader(opts, path, br)
+	}
+	if !opts.BinaryStrings {
+		return fileScan{Skipped: true}
+	}
+	return scanReader(opts, path, newPrintableStrings(br))
+}
+
+// addFileScan adds the outcome of scanning the file path to result.
+func addFileScan(result *Result, path string, fileRes fileScan) {
+	result.Matches = append(result.Matches, fileRes.Matches...)
+	if len(fileRes.Matches) > 0 {
+		result.MatchFiles[path] += len(fileRes.Matches)
+	}
+	result.Warnings = append(result.Warnings, fileRes.Warnings...)
+	result.Suppressed += fileRes.Suppressed
+	result.Ignored += fileRes.Ignored
+	if fileRes.Skipped {
+		result.Skipped++
+	}
+	result.Placeholders = append(result.Placeholders, fileRes.Placeholders...)
+}
+
+// scanReader runs the detection pipeline over the lines of r, attributing
+// findings to path. It is safe to call concurrently.
+func scanReader(opts scanOptions, path string, r io.Reader) fileScan {
+	ls := newLineScanner(opts, path)
+	r, err := ls.readStructured(r)
+	if err != nil {
+		ls.res.Err = err
+		return ls.finish()
+	}
+	reader := newLineReader(r, ls.maxLineLength)
+	for {
+		chunk, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			ls.res.Err = err
+			break
+		}
+		ls.scan(chunk)
+	}
+	return ls.finish()
+}
+
+// lineScanner applies the detection pipeline to successive lines of one file,
+// tracking the state that spans lines: open private key blocks, inline ignore
+// directives, long-line warnings and the key/value pairs of structured files.
+type lineScanner struct {
+	opts          scanOptions
+	path          string
+	maxLineLength int
+	// commit, if non-nil, is attached to every finding.
+	commit *CommitInfo
+
+	res fileScan
+	pem pemTracker
+	// ignores holds the rules suppressed by inline directives, by line.
+	ignores map[int]*ignoreRules
+	// pairs holds the key/value pairs of a structured file, by line.
+	pairs                    map[int][]keyValue
+	longLines, firstLongLine int
+	lastLine                 int
+}
+
+func newLineScanner(opts scanOptions, path string) *lineScanner {
+	maxLineLength := opts.MaxLineLength
+	if maxLineLength <= 0 {
+		maxLineLength = DefaultMaxLineLength
+	}
+	ls := &lineScanner{opts: opts, path: path, maxLineLength: maxLineLength}
+	if opts.Sections != nil {
+		cp, err := opts.Sections.forFile(path)
+		if err != nil {
+			ls.res.Err = err
+		} else {
+			ls.opts.ExcludePatterns, ls.opts.FastPatterns, ls.opts.SlowPatterns = cp.Exclude, cp.Fast, cp.Slow
+		}
+	}
+	return ls
+}
+
+// scan processes one line, or one window of a long line.
+func (ls *lineScanner) scan(chunk lineChunk) {
+	ls.lastLine = chunk.LineNo
+	ls.addIgnores(chunk)
+	if chunk.Windowed && chunk.Skip == 0 {
+		ls.longLines++
+		if ls.firstLongLine == 0 {
+			ls.firstLongLine = chunk.LineNo
+		}
+	}
+
+	matches := scanLine(ls.opts, ls.path, chunk)
+	if ls.pairs != nil {
+		matches = ls.applyStructure(chunk, matches)
+	}
+	matches = ls.dropPlaceholders(chunk, matches)
+
+	// Private key blocks span several lines and supersede any line-level
+	// matches on their marker and body lines.
+	if !chunk.Windowed {
+		ev := ls.pem.feed(ls.path, chunk.LineNo, chunk.Text)
+		ls.emitBlock(ev.Truncated)
+		if ev.Covered != nil {
+			from, to := ev.Covered[0]+1, ev.Covered[1]+1
+			matches = slices.DeleteFunc(matches, func(m Match) bool {
+				return m.Column < to && from < m.EndColumn
+			})
+		}
+		ls.emitBlock(ev.Finding)
+	}
+
+	kept := matches[:0]
+	for _, m := range matches {
+		m.Commit = ls.commit
+		if ls.accept(&m) {
+			kept = append(kept, m)
+		}
+	}
+	matches = kept
+	if len(matches) == 0 {
+		return
+	}
+	ls.res.Matches = append(ls.res.Matches, matches...)
+
+	if ls.opts.OnMatch != nil {
+		lm := LineMatches{Path: ls.path, Line: chunk.LineNo, Text: chunk.Text, Offset: chunk.Offset, Matches: matches}
+		if chunk.Windowed {
+			from := max(0, matches[0].Column-1-chunk.Offset-snippetContext)
+			to := min(len(chunk.Text), matches[len(matches)-1].EndColumn-1-chunk.Offset+snippetContext)
+			lm.Text, lm.Offset = chunk.Text[from:to], chunk.Offset+from
+		}
+		ls.opts.OnMatch(lm)
+	}
+}
+
+// breakBlock ends any open private key block, for input that is not
+// contiguous with what follows, such as separate diff hunks.
+func (ls *lineScanner) breakBlock() {
+	ls.emitBlock(ls.pem.flush(ls.path, ls.lastLine))
+}
+
+func (ls *lineScanner) emitBlock(m *Match) {
+	if m == nil || !ls.accept(m) {
+		return
+	}
+	m.Commit = ls.commit
+	ls.res.Matches = append(ls.res.Matches, *m)
+	if ls.opts.OnMatch != nil {
+		offset := m.Column - 1 - strings.Index(m.LineSnippet, "-----BEGIN")
+		ls.opts.OnMatch(LineMatches{Path: ls.path, Line: m.Line, Text: m.LineSnippet, Offset: offset, Matches: []Match{*m}})
+	}
+}
+
+// addIgnores records the inline ignore directives in chunk.
+func (ls *lineScanner) addIgnores(chunk lineChunk) {
+	same, next := parseIgnoreDirectives(chunk.Text)
+	ls.ignoreLine(chunk.LineNo, same)
+	ls.ignoreLine(chunk.LineNo+1, next)
+}
+
+// ignoreLine suppresses rules on line, in addition to any already suppressed.
+func (ls *lineScanner) ignoreLine(line int, rules *ignoreRules) {
+	if rules == nil {
+		return
+	}
+	if ls.ignores == nil {
+		ls.ignores = make(map[int]*ignoreRules)
+	}
+	if existing := ls.ignores[line]; existing != nil {
+		existing.merge(*rules)
+		return
+	}
+	ls.ignores[line] = rules
+}
+
+// accept sets the fingerprint of m and reports whether it should be
+// reported, counting it as ignored if an inline directive covers it or as
+// suppressed if it is in the baseline. Multi-line findings are ignored by
+// directives on their first line.
+func (ls *lineScanner) accept(m *Match) bool {
+	if rules := ls.ignores[m.Line]; rules != nil && rules.covers(m.RuleID) {
+		ls.res.Ignored++
+		return false
+	}
+	m.Fingerprint = fingerprint(*m)
+	if !m.IsFileFinding() {
+		m.SecretHash = secretHash(*m)
+	}
+	if ls.opts.Baseline.contains(m.Fingerprint) {
+		ls.res.Suppressed++
+		return false
+	}
+	return true
+}
+
+// finish ends the scan, returning its findings and warnings.
+func (ls *lineScanner) finish() fileScan {
+	if ls.res.Err == nil {
+		ls.breakBlock()
+	}
+
+	if ls.longLines > 0 {
+		ls.res.Warnings = append(ls.res.Warnings, ScanWarning{
+			File: ls.path,
+			Line: ls.firstLongLine,
+			Message: fmt.Sprintf("%d line(s) longer than %d bytes were scanned in overlapping windows; snippets are truncated",
+				ls.longLines, ls.maxLineLength),
+		})
+	}
+	return ls.res
+}
+
+// scanLine applies the fast, strict and exclude stages, plus the entropy
+// detector when enabled, to one line (or window of a long line) and returns
+// every non-overlapping match on it in column order.
+func scanLine(opts scanOptions, path string, chunk lineChunk) []Match {
+	line := chunk.Text
+	if len(line) <= 8 {
+		return nil
+	}
+
+	var matches []Match
+	addMatch := func(loc []int, rule *Rule, entropy float64) {
+		// Matches lying entirely within the carried-over overlap were
+		// already reported for the previous window.
+		if loc[1] <= chunk.Skip {
+			return
+		}
+		snippet := strings.TrimSpace(line)
+		if chunk.Windowed {
+			snippet = snippetAround(line, loc)
+		}
+		matches = append(matches, Match{
+			File:        path,
+			Line:        chunk.LineNo,
+			LineSnippet: snippet,
+			MatchText:   line[loc[0]:loc[1]],
+			Column:      chunk.Offset + loc[0] + 1,
+			EndColumn:   chunk.Offset + loc[1] + 1,
+			RuleID:      rule.ID,
+			Severity:    rule.Severity,
+			Entropy:     roundEntropy(entropy),
+			valueText:   valueAfter(line, loc),
+		})
+	}
+
+	var regexLocs [][]int
+	if opts.FastPatterns.MatchString(line) {
+		for _, rm := range opts.SlowPatterns.findAllStringIndex(line) {
+			regexLocs = append(regexLocs, rm.Loc)
+			addMatch(rm.Loc, rm.Rule, shannonEntropy(line[rm.Loc[0]:rm.Loc[1]]))
+		}
+	}
+
+	if opts.Entropy.Enabled {
+		for _, em := range findHighEntropy(opts.Entropy, line) {
+			overlapsRegex := slices.ContainsFunc(regexLocs, func(loc []int) bool {
+				return em.Loc[0] < loc[1] && loc[0] < em.Loc[1]
+			})
+			if !overlapsRegex {
+				addMatch(em.Loc, em.Rule, em.Entropy)
+			}
+		}
+	}
+
+	// Exclude patterns are written against whole source lines; for windows
+	// of a long line, each match's snippet stands in for the line and keeps
+	// the large exclude set affordable.
+	if len(matches) > 0 {
+		if !chunk.Windowed {
+			if opts.ExcludePatterns.MatchString(line) {
+				matches = nil
+			}
+		} else {
+			matches = slices.DeleteFunc(matches, func(m Match) bool {
+				return opts.ExcludePatterns.MatchString(m.LineSnippet)
+			})
+		}
+	}
+
+	// Decoded values are rescanned even on excluded lines, which exclude
+	// long base64 runs wholesale. A finding in a decoded value replaces plain
+	// matches of the encoded value, such as "password: <base64>".
+	if opts.Decode {
+		for _, dm := range scanEncoded(opts, line) {
+			loc := dm.value.Loc
+			matches = slices.DeleteFunc(matches, func(m Match) bool {
+				return m.Encoded == "" && loc[0] < m.EndColumn-1-chunk.Offset && m.Column-1-chunk.Offset < loc[1]
+			})
+			n := len(matches)
+			addMatch(loc, dm.rule, shannonEntropy(dm.text))
+			if len(matches) > n {
+				m := &matches[n]
+				m.MatchText, m.Encoded, m.encodedText = dm.text, dm.value.Encoding, line[loc[0]:loc[1]]
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Column < matches[j].Column
+	})
+	return matches
+}
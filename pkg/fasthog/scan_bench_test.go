@@ -0,0 +1,85 @@
+package fasthog
+
+import (
+	"testing"
+)
+
+// BenchmarkLoadPatterns measures regex loading and compilation performance.
+func BenchmarkLoadPatterns(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadPatterns(PatternFiles{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkHasExtension measures extension matching performance.
+func BenchmarkHasExtension(b *testing.B) {
+	extensions := DefaultExtensions
+	paths := []string{
+		"test.py",
+		"test.js",
+		"test.go",
+		"test.txt",
+		"test.unknown",
+		"very/long/path/to/some/file.py",
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, path := range paths {
+			hasExtension(path, extensions)
+		}
+	}
+}
+
+// BenchmarkRegexMatching measures pattern matching performance.
+func BenchmarkRegexMatching(b *testing.B) {
+	ps := testPatterns(b)
+	exclude, fast, slow := ps.Exclude, ps.Fast, ps.Slow
+
+	testLines := []string{
+		`PASSWORD="mysecretpassword123"`,
+		`AWS_SECRET_ACCESS_KEY=abcdefghijklmnop`,
+		`api_key: "sk_live_1234567890abcdef"`,
+		`const password = "test123"`,
+		`password: str = None`,
+		`ADMIN_PASSWORD: "${ADMIN_PASSWORD}"`,
+	}
+
+	b.Run("fast_patterns", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, line := range testLines {
+				fast.MatchString(line)
+			}
+		}
+	})
+
+	b.Run("strict_patterns", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, line := range testLines {
+				slow.MatchString(line)
+			}
+		}
+	})
+
+	b.Run("exclude_patterns", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, line := range testLines {
+				exclude.MatchString(line)
+			}
+		}
+	})
+
+	b.Run("full_pipeline", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, line := range testLines {
+				if fast.MatchString(line) {
+					if matches := slow.findAllStringIndex(line); len(matches) > 0 && !exclude.MatchString(line) {
+						_ = matches
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package fasthog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+)
+
+// Options configures a Scanner. Fields left at their zero value disable the
+// behaviour they control; DefaultOptions returns the settings the fasthog
+// command uses when no flags are given.
+type Options struct {
+	// Extensions lists the extensions of the files scanned, with their
+	// leading dot.
+	Extensions []string
+	// ExcludeDirs lists directory names skipped in addition to
+	// DefaultExcludeDirs.
+	ExcludeDirs []string
+	// Filenames are base-name globs of files scanned whatever their
+	// extension, such as Dockerfile.
+	Filenames []string
+	// SniffExtensionless scans extensionless files matched by neither
+	// Extensions nor Filenames if their first bytes look like text.
+	SniffExtensionless bool
+	// BinaryStrings scans the printable strings of binary files, which are
+	// otherwise skipped.
+	BinaryStrings bool
+	// Decode rescans base64 and hex values that decode to printable text.
+	Decode bool
+	// Archives controls the scanning of archive members.
+	Archives ArchiveOptions
+	// Gitignore additionally excludes the paths matched by .gitignore files.
+	// The .fasthogignore file at the scan root is always honored.
+	Gitignore bool
+	// Entropy configures the entropy detector.
+	Entropy EntropyOptions
+	// Baseline, if non-nil, holds the fingerprints of accepted findings,
+	// which are dropped from the results.
+	Baseline Baseline
+	// MaxLineLength bounds how many bytes of a single line are matched at
+	// once. Zero means DefaultMaxLineLength.
+	MaxLineLength int
+
+	// OnFile, if non-nil, is invoked whenever a file is about to be scanned.
+	// index is zero-based, total is the total number of files to scan, or
+	// zero if unknown.
+	OnFile func(path string, index, total int)
+	// OnMatch, if non-nil, is invoked once for every line with at least one
+	// finding, as soon as the line is scanned. Files are scanned
+	// concurrently, so it may be called from several goroutines at once.
+	OnMatch func(lm LineMatches)
+}
+
+// DefaultOptions returns the options of a fasthog run without flags.
+func DefaultOptions() Options {
+	return Options{
+		Extensions: DefaultExtensions,
+		Filenames:  DefaultFilenames,
+		Decode:     true,
+		Archives:   DefaultArchiveOptions(),
+		Entropy:    DefaultEntropyOptions(),
+	}
+}
+
+// Scanner finds secrets with a set of patterns and options. It is safe for
+// concurrent use; each scan returns a Result of its own.
+type Scanner struct {
+	opts     Options
+	patterns *Patterns
+}
+
+// New returns a Scanner using patterns, as returned by LoadPatterns, or the
+// embedded default patterns if patterns is nil.
+func New(patterns *Patterns, opts Options) (*Scanner, error) {
+	if patterns == nil {
+		var err error
+		patterns, err = LoadPatterns(PatternFiles{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Scanner{opts: opts, patterns: patterns}, nil
+}
+
+// engineOptions returns the engine options for a scan of dir stopped by ctx.
+func (s *Scanner) engineOptions(ctx context.Context, dir string) scanOptions {
+	return scanOptions{
+		Directory:          dir,
+		Extensions:         s.opts.Extensions,
+		ExcludeDirs:        s.opts.ExcludeDirs,
+		Filenames:          s.opts.Filenames,
+		SniffExtensionless: s.opts.SniffExtensionless,
+		BinaryStrings:      s.opts.BinaryStrings,
+		Decode:             s.opts.Decode,
+		Archives:           s.opts.Archives,
+		ExcludePatterns:    s.patterns.Exclude,
+		FastPatterns:       s.patterns.Fast,
+		SlowPatterns:       s.patterns.Slow,
+		Sections:           s.patterns,
+		Gitignore:          s.opts.Gitignore,
+		Entropy:            s.opts.Entropy,
+		Baseline:           s.opts.Baseline,
+		MaxLineLength:      s.opts.MaxLineLength,
+		OnCurrentFile:      s.opts.OnFile,
+		OnMatch:            s.opts.OnMatch,
+		Context:            ctx,
+	}
+}
+
+// ScanFS scans the files of fsys as a directory is scanned, attributing
+// findings to their paths within fsys. If ctx is done before the scan
+// completes, the findings so far are returned with ctx's error.
+func (s *Scanner) ScanFS(ctx context.Context, fsys fs.FS) (Result, error) {
+	opts := s.engineOptions(ctx, "")
+	open := func(path string) (io.ReadCloser, error) {
+		return fsys.Open(path)
+	}
+	name := func(path string) string { return path }
+	res := scanFS(opts, fsys, name, open)
+	return res, opts.contextErr()
+}
+
+// ScanDirectory scans the files under dir, attributing findings to paths
+// relative to it.
+func (s *Scanner) ScanDirectory(ctx context.Context, dir string) (Result, error) {
+	opts := s.engineOptions(ctx, dir)
+	res := scanDirectory(opts)
+	return res, opts.contextErr()
+}
+
+// ScanPaths scans a mix of files, directories and StdinTarget. Files are
+// scanned whatever their extension and attributed to the path given;
+// findings in directories are attributed to paths joined with the
+// directory.
+func (s *Scanner) ScanPaths(ctx context.Context, paths []string) (Result, error) {
+	opts := s.engineOptions(ctx, "")
+	res := scanTargets(opts, paths)
+	return res, opts.contextErr()
+}
+
+// ScanReader scans the content of r, whatever the extension of name, and
+// attributes the findings to name. Archives are scanned member by member and
+// sensitive file names are reported as for files on disk.
+func (s *Scanner) ScanReader(ctx context.Context, name string, r io.Reader) (Result, error) {
+	opts := s.engineOptions(ctx, "")
+	if err := opts.contextErr(); err != nil {
+		return Result{MatchFiles: make(map[string]int)}, err
+	}
+
+	// A sensitive file whose rule checks content is read twice, so it is
+	// buffered; such files are small.
+	var data []byte
+	if fr := matchSensitiveFile(name); fr != nil && fr.Content != nil {
+		var err error
+		if data, err = io.ReadAll(r); err != nil {
+			res := Result{MatchFiles: make(map[string]int), Filenames: []string{name}}
+			res.Errors = append(res.Errors, ScanError{File: name, Op: "read", Err: err})
+			return res, nil
+		}
+		r = bytes.NewReader(data)
+	}
+
+	res := scanFile(opts, name, r)
+	res.Filenames = append([]string{name}, res.Filenames...)
+	scanSensitiveFiles(opts, &res, []string{name}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	return res, nil
+}
+
+// ScanGitHistory scans the lines added by every commit reachable from the
+// branches selected by hist in the git repository at dir, attributing each
+// finding to its commit.
+func (s *Scanner) ScanGitHistory(ctx context.Context, dir string, hist GitHistoryOptions) (Result, error) {
+	return scanGitHistory(s.engineOptions(ctx, dir), hist)
+}
+
+// ScanStaged scans the lines added or modified in the index of the git
+// repository at dir, that is, the changes about to be committed.
+func (s *Scanner) ScanStaged(ctx context.Context, dir string) (Result, error) {
+	return scanStaged(s.engineOptions(ctx, dir))
+}
+
+// Rules returns every rule that can produce findings: the strict pattern
+// rules, including those scoped to some files, the private key block rules,
+// the structured file rule, the sensitive file rules and, when enabled, the
+// entropy rules.
+func (s *Scanner) Rules() []Rule {
+	return activeRules(s.engineOptions(context.Background(), ""))
+}
+
+// activeRules returns every rule that can produce findings with opts, as
+// listed by Scanner.Rules.
+func activeRules(opts scanOptions) []Rule {
+	var rules []Rule
+	if opts.Sections != nil {
+		rules = append(rules, opts.Sections.slow...)
+	} else if opts.SlowPatterns != nil {
+		rules = append(rules, opts.SlowPatterns.Rules...)
+	}
+	rules = append(rules, privateKeyBlockRule, privateKeyExampleRule, structuredSecretRule)
+	for _, fr := range sensitiveFileRules {
+		rules = append(rules, fr.Rule)
+	}
+	if opts.Entropy.Enabled {
+		rules = append(rules, entropyBase64Rule, entropyHexRule)
+	}
+	return rules
+}
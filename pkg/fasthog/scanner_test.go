@@ -0,0 +1,189 @@
+package fasthog
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestScanner(t *testing.T, opts Options) *Scanner {
+	t.Helper()
+	s, err := New(nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// testPatterns returns the embedded default patterns.
+func testPatterns(t testing.TB) *Patterns {
+	t.Helper()
+	ps, err := LoadPatterns(PatternFiles{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ps
+}
+
+// testScanOptions returns opts set to scan with the embedded default
+// patterns, including those scoped to some files, as a Scanner does.
+func testScanOptions(t testing.TB, opts scanOptions) scanOptions {
+	t.Helper()
+	ps := testPatterns(t)
+	opts.ExcludePatterns, opts.FastPatterns, opts.SlowPatterns, opts.Sections = ps.Exclude, ps.Fast, ps.Slow, ps
+	return opts
+}
+
+func TestScannerScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/config.yml":          {Data: []byte("password: secret456\n")},
+		"src/notes.txt":           {Data: []byte("password: secret789\n")},
+		"node_modules/pkg/a.yml":  {Data: []byte("password: secret123\n")},
+		"deploy/prod.tfstate":     {Data: []byte("{}\n")},
+		"src/clean.yml":           {Data: []byte("name: fasthog\n")},
+		"src/deep/nested/app.yml": {Data: []byte("api_key: sk_test_abc\n")},
+	}
+	opts := DefaultOptions()
+	opts.Extensions = []string{".yml"}
+	s := newTestScanner(t, opts)
+
+	res, err := s.ScanFS(context.Background(), fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Errors) != 0 {
+		t.Errorf("unexpected errors: %v", res.Errors)
+	}
+	for _, want := range []string{"src/config.yml", "src/deep/nested/app.yml", "deploy/prod.tfstate"} {
+		if res.MatchFiles[want] == 0 {
+			t.Errorf("expected a finding in %s, got %v", want, res.MatchFiles)
+		}
+	}
+	for _, unwanted := range []string{"src/notes.txt", "node_modules/pkg/a.yml", "src/clean.yml"} {
+		if res.MatchFiles[unwanted] != 0 {
+			t.Errorf("unexpected finding in %s", unwanted)
+		}
+	}
+	if slices.Contains(res.Filenames, "node_modules/pkg/a.yml") {
+		t.Error("excluded directory should not be scanned")
+	}
+}
+
+func TestScannerScanReader(t *testing.T) {
+	s := newTestScanner(t, DefaultOptions())
+
+	t.Run("content", func(t *testing.T) {
+		// The extension is not checked for a named reader.
+		res, err := s.ScanReader(context.Background(), "stdin.txt", strings.NewReader("# settings\npassword: secret456\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Matches) != 1 {
+			t.Fatalf("expected 1 match, got %+v", res.Matches)
+		}
+		if m := res.Matches[0]; m.File != "stdin.txt" || m.Line != 2 {
+			t.Errorf("unexpected match location %s:%d", m.File, m.Line)
+		}
+		if !slices.Equal(res.Filenames, []string{"stdin.txt"}) {
+			t.Errorf("unexpected filenames %v", res.Filenames)
+		}
+	})
+
+	t.Run("sensitive file", func(t *testing.T) {
+		res, err := s.ScanReader(context.Background(), ".npmrc", strings.NewReader("registry=https://registry.npmjs.org/\n//registry.npmjs.org/:_auth=dXNlcjpwYXNz\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.ContainsFunc(res.Matches, func(m Match) bool { return m.File == ".npmrc" }) {
+			t.Errorf("expected a finding for .npmrc, got %+v", res.Matches)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		res, err := s.ScanReader(ctx, "config.yml", strings.NewReader("password: secret456\n"))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if len(res.Matches) != 0 {
+			t.Errorf("expected no matches, got %+v", res.Matches)
+		}
+	})
+}
+
+func TestScannerOnMatch(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.yml": {Data: []byte("password: secret123\n")},
+		"b.yml": {Data: []byte("api_key: sk_test_abc\ntoken: xyz789\n")},
+	}
+	var (
+		mu       sync.Mutex
+		streamed int
+		files    []string
+	)
+	opts := DefaultOptions()
+	opts.OnFile = func(path string, index, total int) {
+		mu.Lock()
+		files = append(files, path)
+		mu.Unlock()
+	}
+	opts.OnMatch = func(lm LineMatches) {
+		mu.Lock()
+		streamed += len(lm.Matches)
+		mu.Unlock()
+	}
+	s := newTestScanner(t, opts)
+
+	res, err := s.ScanFS(context.Background(), fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streamed == 0 || streamed != len(res.Matches) {
+		t.Errorf("streamed %d matches, result has %d", streamed, len(res.Matches))
+	}
+	slices.Sort(files)
+	if !slices.Equal(files, []string{"a.yml", "b.yml"}) {
+		t.Errorf("unexpected progress callbacks for %v", files)
+	}
+}
+
+func TestScannerCanceled(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.yml": {Data: []byte("password: secret123\n")},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s := newTestScanner(t, DefaultOptions())
+
+	res, err := s.ScanFS(ctx, fsys)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(res.Matches) != 0 {
+		t.Errorf("expected no matches after cancellation, got %+v", res.Matches)
+	}
+}
+
+func TestScannerRules(t *testing.T) {
+	opts := DefaultOptions()
+	rules := newTestScanner(t, opts).Rules()
+	for _, id := range []string{privateKeyBlockRule.ID, structuredSecretRule.ID, "ssh-private-key-file"} {
+		if !slices.ContainsFunc(rules, func(r Rule) bool { return r.ID == id }) {
+			t.Errorf("expected rule %s", id)
+		}
+	}
+	if slices.ContainsFunc(rules, func(r Rule) bool { return r.ID == entropyBase64Rule.ID }) {
+		t.Error("entropy rules should only be listed when enabled")
+	}
+
+	opts.Entropy.Enabled = true
+	rules = newTestScanner(t, opts).Rules()
+	if !slices.ContainsFunc(rules, func(r Rule) bool { return r.ID == entropyBase64Rule.ID }) {
+		t.Error("expected the entropy rules when enabled")
+	}
+}
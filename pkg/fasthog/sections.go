@@ -0,0 +1,210 @@
+package fasthog
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// sectionHeader matches a pattern file line such as "[files: *.json]" or
+// "[files: *.tf, *.tfvars]". The patterns after it, up to the next header or
+// the end of the file, apply only to files whose base name matches one of
+// its comma-separated globs; a "[files: *]" header returns to patterns that
+// apply to every file. The "files:" prefix keeps a pattern that is a single
+// bracket expression, such as [abc], from reading as a header.
+var sectionHeader = regexp.MustCompile(`^\[files:([^\[\]]*)\]\s*$`)
+
+// allFiles is the scope of the patterns outside any section.
+const allFiles = ""
+
+// parseSectionHeader returns the scope of the section header line: its globs
+// joined with ", ", or allFiles for "[files: *]". ok is false if line is not
+// a header.
+func parseSectionHeader(line string) (scope string, ok bool, err error) {
+	m := sectionHeader.FindStringSubmatch(line)
+	if m == nil {
+		return "", false, nil
+	}
+	var globs []string
+	for _, glob := range strings.Split(m[1], ",") {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			return "", true, fmt.Errorf("invalid glob %q in section header", glob)
+		}
+		globs = append(globs, glob)
+	}
+	if len(globs) == 0 {
+		return "", true, fmt.Errorf("empty section header")
+	}
+	if slices.Contains(globs, "*") {
+		return allFiles, true, nil
+	}
+	return strings.Join(globs, ", "), true, nil
+}
+
+// scopeMatches reports whether the slash-separated path p is in scope.
+func scopeMatches(scope, p string) bool {
+	return scope == allFiles || matchesFilename(p, strings.Split(scope, ", "))
+}
+
+// scopedPattern is one line of an exclude or fast pattern file.
+type scopedPattern struct {
+	Pattern string
+	// Scope is the section the pattern belongs to.
+	Scope string
+}
+
+// readPatterns reads the patterns of one or more exclude or fast pattern
+// files. Lines starting with '#' are treated as comments and ignored.
+func readPatterns(filesystem fs.FS, paths ...string) ([]scopedPattern, error) {
+	var patterns []scopedPattern
+	for _, p := range paths {
+		b, err := fs.ReadFile(filesystem, p)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load regexes from %s: %w", p, err)
+		}
+		scope := allFiles
+		for i, raw := range bytes.Split(b, []byte("\n")) {
+			line := strings.TrimSuffix(string(raw), "\r")
+			if len(line) == 0 || line[0] == '#' {
+				continue
+			}
+			if s, ok, err := parseSectionHeader(line); ok {
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: %w", p, i+1, err)
+				}
+				scope = s
+				continue
+			}
+			patterns = append(patterns, scopedPattern{Pattern: shellReplacer.Replace(line), Scope: scope})
+		}
+	}
+	return patterns, nil
+}
+
+// compilePatterns combines the patterns whose scope is allFiles or one of
+// scopes into a single regex. No patterns compile to a regex matching
+// nothing.
+func compilePatterns(patterns []scopedPattern, scopes []string) (*regexp.Regexp, error) {
+	var regexes []string
+	for _, p := range patterns {
+		if p.Scope == allFiles || slices.Contains(scopes, p.Scope) {
+			regexes = append(regexes, "("+p.Pattern+")")
+		}
+	}
+	if len(regexes) == 0 {
+		regexes = append(regexes, matchNothing)
+	}
+	compiled, err := regexp.Compile(strings.Join(regexes, "|"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regex patterns: %w", err)
+	}
+	return compiled, nil
+}
+
+// compiledPatterns is the exclude, fast and strict patterns applied to a
+// file.
+type compiledPatterns struct {
+	Exclude, Fast *regexp.Regexp
+	Slow          *RuleSet
+}
+
+// Patterns holds the exclude, fast and strict patterns, including those in
+// sections, and compiles the combination that applies to each file on first
+// use.
+type Patterns struct {
+	// compiledPatterns holds the patterns that apply to every file.
+	compiledPatterns
+
+	exclude, fast []scopedPattern
+	slow          []Rule
+	// scopes lists the distinct sections, in the order they first appear.
+	scopes []string
+
+	mu sync.Mutex
+	// compiled caches the patterns for each combination of scopes, by the
+	// scopes joined with newlines.
+	compiled map[string]*compiledPatterns
+}
+
+// newPatternSet compiles the patterns that apply to every file and, to
+// report errors up front, those of each section.
+func newPatternSet(exclude, fast []scopedPattern, slow []Rule) (*Patterns, error) {
+	ps := &Patterns{exclude: exclude, fast: fast, slow: slow, compiled: make(map[string]*compiledPatterns)}
+	for _, p := range exclude {
+		ps.addScope(p.Scope)
+	}
+	for _, p := range fast {
+		ps.addScope(p.Scope)
+	}
+	for _, r := range slow {
+		ps.addScope(r.Files)
+	}
+
+	all, err := ps.compile(nil)
+	if err != nil {
+		return nil, err
+	}
+	ps.compiledPatterns = *all
+	for _, scope := range ps.scopes {
+		if _, err := ps.compile([]string{scope}); err != nil {
+			return nil, fmt.Errorf("section [%s]: %w", scope, err)
+		}
+	}
+	return ps, nil
+}
+
+func (ps *Patterns) addScope(scope string) {
+	if scope != allFiles && !slices.Contains(ps.scopes, scope) {
+		ps.scopes = append(ps.scopes, scope)
+	}
+}
+
+// forFile returns the patterns that apply to the slash-separated path p.
+func (ps *Patterns) forFile(p string) (*compiledPatterns, error) {
+	var scopes []string
+	for _, scope := range ps.scopes {
+		if scopeMatches(scope, p) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return ps.compile(scopes)
+}
+
+// compile returns the patterns that apply to files in scopes, compiling them
+// if needed.
+func (ps *Patterns) compile(scopes []string) (*compiledPatterns, error) {
+	key := strings.Join(scopes, "\n")
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if cp, ok := ps.compiled[key]; ok {
+		return cp, nil
+	}
+
+	exclude, err := compilePatterns(ps.exclude, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("exclude patterns: %w", err)
+	}
+	fast, err := compilePatterns(ps.fast, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("fast patterns: %w", err)
+	}
+	slow, err := compileRules(slices.DeleteFunc(slices.Clone(ps.slow), func(r Rule) bool {
+		return r.Files != allFiles && !slices.Contains(scopes, r.Files)
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("strict patterns: %w", err)
+	}
+
+	cp := &compiledPatterns{Exclude: exclude, Fast: fast, Slow: slow}
+	ps.compiled[key] = cp
+	return cp, nil
+}
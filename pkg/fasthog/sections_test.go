@@ -0,0 +1,118 @@
+package fasthog
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseSectionHeader(t *testing.T) {
+	tests := []struct {
+		line  string
+		scope string
+		ok    bool
+	}{
+		{"[files: *.json]", "*.json", true},
+		{"[files:*.tf,*.tfvars]  ", "*.tf, *.tfvars", true},
+		{"[files: package-lock.json, .env.*]", "package-lock.json, .env.*", true},
+		{"[files: *]", allFiles, true},
+		{"[*.json]", "", false},
+		{"[abc]", "", false},
+		{`[A-Za-z0-9+\/]{120}$`, "", false},
+		{`[A-Z_].{8,12}=\"\$`, "", false},
+		{"password", "", false},
+	}
+	for _, tt := range tests {
+		scope, ok, err := parseSectionHeader(tt.line)
+		if err != nil || scope != tt.scope || ok != tt.ok {
+			t.Errorf("parseSectionHeader(%q) = %q, %v, %v, want %q, %v", tt.line, scope, ok, err, tt.scope, tt.ok)
+		}
+	}
+	if _, ok, err := parseSectionHeader("[files: , ]"); !ok || err == nil {
+		t.Errorf("expected an error for an empty section header, got %v, %v", ok, err)
+	}
+}
+
+// TestPatternSetSections verifies that each file is scanned with the
+// patterns that apply to every file plus those of the sections matching its
+// name.
+func TestPatternSetSections(t *testing.T) {
+	testFS := fstest.MapFS{
+		"exclude.regex": &fstest.MapFile{Data: []byte("unused_everywhere\n[files: *.tf, *.tfvars]\nvault\\.\n[files: *]\nexample_only\n")},
+		"fast.regex":    &fstest.MapFile{Data: []byte("(?i)password\n[files: *.json]\n\"secret\"\n")},
+		"strict.regex": &fstest.MapFile{Data: []byte(`# @rule id=password-assignment
+(?i)password\s*=\s*\S+
+[files: *.json]
+# @rule id=json-secret
+"secret":\s*"[^"]+"
+`)},
+	}
+	exclude, err := readPatterns(testFS, "exclude.regex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := readPatterns(testFS, "fast.regex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, err := readRules(testFS, "strict.regex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := newPatternSet(exclude, fast, slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps.Slow.Rules) != 1 || slow[1].Files != "*.json" {
+		t.Fatalf("expected json-secret to be scoped to *.json, got %+v", slow)
+	}
+
+	opts := scanOptions{ExcludePatterns: ps.Exclude, FastPatterns: ps.Fast, SlowPatterns: ps.Slow, Sections: ps}
+	tests := []struct {
+		path    string
+		content string
+		rules   []string
+	}{
+		{"main.tf", "password = vault.db_password", nil},
+		{"main.py", "password = vault.db_password", []string{"password-assignment"}},
+		{"example_only.tf", "password = example_only", nil},
+		{"config.json", `{"secret": "hunter2hunter2"}`, []string{"json-secret"}},
+		{"config.yaml", `{"secret": "hunter2hunter2"}`, nil},
+		{"app.jar!/config.json", `{"secret": "hunter2hunter2"}`, []string{"json-secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			res := scanReader(opts, tt.path, strings.NewReader(tt.content))
+			if res.Err != nil {
+				t.Fatal(res.Err)
+			}
+			var got []string
+			for _, m := range res.Matches {
+				got = append(got, m.RuleID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.rules, ",") {
+				t.Errorf("got rules %v, want %v", got, tt.rules)
+			}
+		})
+	}
+}
+
+// TestDefaultPatternSections verifies the sections of the embedded exclude
+// patterns.
+func TestDefaultPatternSections(t *testing.T) {
+	ps, err := LoadPatterns(PatternFiles{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `"resolved": "https://registry.npmjs.org/left-pad/-/left-pad-1.3.0.tgz"`
+	lock, err := ps.forFile("web/package-lock.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lock.Exclude.MatchString(line) {
+		t.Error("package-lock.json exclusions do not apply to package-lock.json")
+	}
+	if ps.Exclude.MatchString(line) {
+		t.Error("package-lock.json exclusions apply to every file")
+	}
+}
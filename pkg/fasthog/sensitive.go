@@ -0,0 +1,164 @@
+package fasthog
+
+import (
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sensitiveFileSource is the Source of rules matched by file name rather
+// than content.
+const sensitiveFileSource = "filename"
+
+// sensitiveFileContentLimit bounds how much of a file is read to confirm a
+// rule with a Content pattern.
+const sensitiveFileContentLimit = 64 << 10
+
+// fileRule reports a file as a finding by its name alone, or by its name and
+// a confirming content pattern.
+type fileRule struct {
+	Rule
+	// Names are path.Match patterns for the file's base name, matched
+	// case-insensitively.
+	Names []string
+	// Content, if non-nil, must match the start of the file.
+	Content *regexp.Regexp
+}
+
+// sensitiveFileRules are files that are a finding by their mere presence.
+var sensitiveFileRules = []fileRule{
+	{
+		Rule:  Rule{ID: "ssh-private-key-file", Description: "SSH private key file", SecretType: "private-key", Severity: SeverityCritical},
+		Names: []string{"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519"},
+	},
+	{
+		Rule:  Rule{ID: "pem-file", Description: "PEM file containing a private key", SecretType: "private-key", Severity: SeverityHigh},
+		Names: []string{"*.pem"},
+		// Certificates and public keys are routinely committed.
+		Content: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`),
+	},
+	{
+		Rule:  Rule{ID: "pkcs12-file", Description: "PKCS#12 certificate and key bundle", SecretType: "private-key", Severity: SeverityHigh},
+		Names: []string{"*.p12", "*.pfx"},
+	},
+	{
+		Rule:  Rule{ID: "java-keystore-file", Description: "Java keystore", SecretType: "private-key", Severity: SeverityHigh},
+		Names: []string{"*.keystore", "*.jks"},
+	},
+	{
+		Rule:  Rule{ID: "htpasswd-file", Description: "Apache htpasswd password file", SecretType: "password", Severity: SeverityHigh},
+		Names: []string{".htpasswd"},
+	},
+	{
+		Rule:  Rule{ID: "credentials-json-file", Description: "Cloud service account or OAuth credentials file", SecretType: "cloud-credentials", Severity: SeverityHigh},
+		Names: []string{"credentials.json"},
+	},
+	{
+		Rule:  Rule{ID: "terraform-state-file", Description: "Terraform state, which stores resource secrets in plain text", SecretType: "terraform-state", Severity: SeverityHigh},
+		Names: []string{"*.tfstate", "*.tfstate.backup"},
+	},
+	{
+		Rule:    Rule{ID: "npmrc-auth-file", Description: "npm configuration with registry credentials", SecretType: "token", Severity: SeverityHigh},
+		Names:   []string{".npmrc"},
+		Content: regexp.MustCompile(`(?m)(?:^|:)_(?:authToken|auth|password)\s*=\s*\S`),
+	},
+}
+
+func init() {
+	for i := range sensitiveFileRules {
+		sensitiveFileRules[i].Source = sensitiveFileSource
+	}
+}
+
+// matchSensitiveFile returns the rule whose names match the base name of the
+// slash-separated path p, or nil.
+func matchSensitiveFile(p string) *fileRule {
+	base := strings.ToLower(path.Base(p))
+	for i := range sensitiveFileRules {
+		fr := &sensitiveFileRules[i]
+		if slices.ContainsFunc(fr.Names, func(pattern string) bool {
+			ok, _ := path.Match(pattern, base)
+			return ok
+		}) {
+			return fr
+		}
+	}
+	return nil
+}
+
+// IsFileFinding reports whether m reports a whole file, found by its name,
+// rather than a location within it.
+func (m Match) IsFileFinding() bool {
+	return m.Line == 0
+}
+
+// scanSensitiveFiles reports the files among filenames that match a
+// sensitive file rule, opening those whose rule checks content, and adds the
+// findings to result. A file is reported by name even if its content produced
+// findings of its own; files not already scanned for content are added to
+// result.Filenames.
+func scanSensitiveFiles(opts scanOptions, result *Result, filenames []string, open func(path string) (io.ReadCloser, error)) {
+	for _, name := range filenames {
+		fr := matchSensitiveFile(name)
+		if fr == nil {
+			continue
+		}
+		if fr.Content != nil {
+			matched, err := fileStartMatches(name, fr.Content, open)
+			if err != nil {
+				result.Errors = append(result.Errors, ScanError{File: name, Op: "read", Err: err})
+				continue
+			}
+			if !matched {
+				continue
+			}
+		}
+		if !slices.Contains(result.Filenames, name) {
+			result.Filenames = append(result.Filenames, name)
+		}
+
+		ls := newLineScanner(opts, name)
+		ls.emitFile(fr)
+		result.Matches = append(result.Matches, ls.res.Matches...)
+		if len(ls.res.Matches) > 0 {
+			result.MatchFiles[name] += len(ls.res.Matches)
+		}
+		result.Suppressed += ls.res.Suppressed
+	}
+}
+
+// fileStartMatches reports whether re matches the first
+// sensitiveFileContentLimit bytes of the file name.
+func fileStartMatches(name string, re *regexp.Regexp, open func(path string) (io.ReadCloser, error)) (bool, error) {
+	f, err := open(name)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close() // Read-only; nothing to flush
+	}()
+	data, err := io.ReadAll(io.LimitReader(f, sensitiveFileContentLimit))
+	if err != nil {
+		return false, err
+	}
+	return re.Match(data), nil
+}
+
+// emitFile reports the scanner's file as a finding of fr.
+func (ls *lineScanner) emitFile(fr *fileRule) {
+	m := Match{
+		File:      ls.path,
+		MatchText: path.Base(ls.path),
+		RuleID:    fr.ID,
+		Severity:  fr.Severity,
+	}
+	if !ls.accept(&m) {
+		return
+	}
+	ls.res.Matches = append(ls.res.Matches, m)
+	if ls.opts.OnMatch != nil {
+		ls.opts.OnMatch(LineMatches{Path: ls.path, Text: fr.Description, Matches: []Match{m}})
+	}
+}
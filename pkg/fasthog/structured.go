@@ -0,0 +1,506 @@
+package fasthog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Structured file formats, as returned by structuredFormat.
+const (
+	formatEnv        = "env"
+	formatProperties = "properties"
+	formatJSON       = "json"
+	formatYAML       = "yaml"
+)
+
+// maxStructuredSize bounds the size of a file parsed for its key/value
+// pairs; larger files are only scanned line by line.
+const maxStructuredSize = 4 << 20
+
+// minStructuredValueLength is the shortest literal value of a secret-like key
+// reported by itself. Shorter values are flags and units more often than
+// credentials.
+const minStructuredValueLength = 4
+
+// structuredSecretRule reports a secret-like key with a literal value that no
+// pattern matched.
+var structuredSecretRule = Rule{
+	ID:          "structured-secret",
+	Description: "Secret-like key assigned a literal value in a YAML, JSON, .env or properties file",
+	SecretType:  "password",
+	Severity:    SeverityHigh,
+}
+
+// secretKeyWords are the last words of keys whose values are secrets, as in
+// DB_PASSWORD or authToken.
+var secretKeyWords = map[string]bool{
+	"password": true, "passwd": true, "passphrase": true, "pass": true, "pwd": true,
+	"secret": true, "token": true, "credential": true, "credentials": true, "apikey": true,
+}
+
+// secretKeyQualifiers are the words that make a key ending in "key" a secret,
+// as in api_key or PRIVATE_KEY; on its own, "key" is too common.
+var secretKeyQualifiers = map[string]bool{
+	"api": true, "access": true, "private": true, "secret": true, "auth": true,
+	"signing": true, "encryption": true, "master": true, "account": true,
+	"storage": true, "subscription": true,
+}
+
+// keyValue is a key/value pair parsed from a structured file.
+type keyValue struct {
+	Key   string
+	Value string
+	Line  int
+	// KeyColumn, ValueColumn and ValueEnd are 1-based byte offsets within
+	// the line; ValueEnd is one past the last byte of the value, which
+	// excludes any quotes.
+	KeyColumn, ValueColumn, ValueEnd int
+}
+
+// structuredFormat returns the format of the slash-separated path p whose
+// key/value pairs can be parsed, or "" for other files.
+func structuredFormat(p string) string {
+	base := strings.ToLower(path.Base(p))
+	switch {
+	case base == ".env" || base == ".envrc" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env"):
+		return formatEnv
+	case strings.HasSuffix(base, ".properties"):
+		return formatProperties
+	case strings.HasSuffix(base, ".json"):
+		return formatJSON
+	case strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml"):
+		return formatYAML
+	}
+	return ""
+}
+
+// parseStructured returns the key/value pairs of data in format. Pairs whose
+// value is not a single-line literal, such as nested mappings, lists, block
+// scalars and YAML aliases, are left out.
+func parseStructured(format string, data []byte) []keyValue {
+	if format == formatJSON {
+		return parseJSONPairs(data)
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	switch format {
+	case formatEnv:
+		return parseEnvPairs(lines)
+	case formatProperties:
+		return parsePropertiesPairs(lines)
+	case formatYAML:
+		return parseYAMLPairs(lines)
+	}
+	return nil
+}
+
+// envAssignment matches the key of a .env assignment, optionally exported.
+var envAssignment = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_.-]*)\s*=[ \t]*`)
+
+// parseEnvPairs parses KEY=value lines, with single- or double-quoted or
+// unquoted values.
+func parseEnvPairs(lines []string) []keyValue {
+	var pairs []keyValue
+	for i, line := range lines {
+		m := envAssignment.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		from, to := scalarSpan(line, m[1])
+		pairs = append(pairs, keyValue{
+			Key: line[m[2]:m[3]], Value: line[from:to], Line: i + 1,
+			KeyColumn: m[2] + 1, ValueColumn: from + 1, ValueEnd: to + 1,
+		})
+	}
+	return pairs
+}
+
+// parsePropertiesPairs parses Java properties: a key ended by '=', ':' or
+// whitespace, then its value. Continuation lines are not joined; a pair's
+// value is its first line.
+func parsePropertiesPairs(lines []string) []keyValue {
+	var pairs []keyValue
+	continued := false
+	for i, line := range lines {
+		wasContinued := continued
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		continued = trailing%2 == 1
+		if wasContinued {
+			continue
+		}
+
+		start := len(line) - len(strings.TrimLeft(line, " \t\f"))
+		if start == len(line) || line[start] == '#' || line[start] == '!' {
+			continue
+		}
+		end := start
+		for end < len(line) && !strings.ContainsRune("=: \t\f", rune(line[end])) {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end, len(line))
+
+		from := end
+		for from < len(line) && (line[from] == ' ' || line[from] == '\t' || line[from] == '\f') {
+			from++
+		}
+		if from < len(line) && (line[from] == '=' || line[from] == ':') {
+			from++
+		}
+		for from < len(line) && (line[from] == ' ' || line[from] == '\t' || line[from] == '\f') {
+			from++
+		}
+		value := strings.TrimRight(line[from:], " \t\f")
+		if continued {
+			value = strings.TrimSuffix(value, `\`)
+		}
+		pairs = append(pairs, keyValue{
+			Key: line[start:end], Value: value, Line: i + 1,
+			KeyColumn: start + 1, ValueColumn: from + 1, ValueEnd: from + len(value) + 1,
+		})
+	}
+	return pairs
+}
+
+// jsonFrame is an object or array being parsed by parseJSONPairs.
+type jsonFrame struct {
+	object bool
+	// key is the current member name of an object, and keyColumn and
+	// keyLine its location; expectKey is set while the next token is a
+	// member name.
+	key       string
+	keyLine   int
+	keyColumn int
+	expectKey bool
+}
+
+// parseJSONPairs returns the object members of data whose values are
+// strings. Parsing stops at the first syntax error, keeping the pairs found
+// before it.
+func parseJSONPairs(data []byte) []keyValue {
+	locate := lineLocator(data)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var (
+		pairs []keyValue
+		stack []*jsonFrame
+	)
+	// valueDone records that the current member's value has ended.
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].expectKey = true
+		}
+	}
+	for {
+		prev := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return pairs
+		}
+		end := int(dec.InputOffset())
+
+		switch tok := tok.(type) {
+		case json.Delim:
+			switch tok {
+			case '{', '[':
+				stack = append(stack, &jsonFrame{object: tok == '{', expectKey: tok == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+				valueDone()
+			}
+			continue
+		case string:
+			// The token's raw text is the quoted string ending at end.
+			quote := prev + bytes.IndexByte(data[prev:end], '"')
+			var top *jsonFrame
+			if len(stack) > 0 {
+				top = stack[len(stack)-1]
+			}
+			if top != nil && top.object && top.expectKey {
+				top.key, top.expectKey = tok, false
+				top.keyLine, top.keyColumn = locate(quote)
+				continue
+			}
+			if top != nil && top.object {
+				line, column := locate(quote + 1)
+				if line == top.keyLine {
+					pairs = append(pairs, keyValue{
+						Key: top.key, Value: tok, Line: line,
+						KeyColumn: top.keyColumn, ValueColumn: column, ValueEnd: column + end - 1 - (quote + 1),
+					})
+				}
+			}
+		}
+		valueDone()
+	}
+}
+
+// lineLocator returns a function converting a byte offset in data to a
+// 1-based line and column.
+func lineLocator(data []byte) func(offset int) (line, column int) {
+	starts := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return func(offset int) (int, int) {
+		i := sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+		return i + 1, offset - starts[i] + 1
+	}
+}
+
+// yamlKey matches a YAML mapping key, possibly in a list item, and the
+// separator after it.
+var yamlKey = regexp.MustCompile(`^(\s*(?:-\s+)*)("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s#'"{\[\]},&*!|>%@` + "`" + `-][^#]*?|-[^\s#][^#]*?)\s*:(?:\s+|$)`)
+
+// yamlBooleans are plain scalars that YAML reads as null or booleans rather
+// than strings.
+var yamlBooleans = map[string]bool{"~": true, "null": true, "true": true, "false": true, "yes": true, "no": true, "on": true, "off": true}
+
+// parseYAMLPairs parses "key: value" lines of block mappings, including the
+// first member of list items. Values that are not single-line scalars, and
+// the content of block scalars, are skipped.
+func parseYAMLPairs(lines []string) []keyValue {
+	var pairs []keyValue
+	blockIndent := -1
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			if strings.TrimSpace(line) == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+
+		m := yamlKey.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		key := line[m[4]:m[5]]
+		if key[0] == '"' || key[0] == '\'' {
+			key = unquoteYAML(key)
+		}
+
+		start := m[1]
+		if strings.HasPrefix(line[start:], "&") {
+			// An anchor names the value that follows it.
+			anchor := strings.IndexAny(line[start:], " \t")
+			if anchor < 0 {
+				continue
+			}
+			start += anchor + 1
+			for start < len(line) && line[start] == ' ' {
+				start++
+			}
+		}
+		if tag, ok := strings.CutPrefix(line[start:], "!!str "); ok {
+			start = len(line) - len(strings.TrimLeft(tag, " "))
+		}
+		if start == len(line) {
+			continue
+		}
+		switch line[start] {
+		case '|', '>':
+			// The block's content is indented further than its key.
+			blockIndent = m[4]
+			continue
+		case '*', '!', '{', '[', '#':
+			// Aliases, tags, flow collections and comments.
+			continue
+		}
+
+		from, to := scalarSpan(line, start)
+		value := line[from:to]
+		if line[start] != '"' && line[start] != '\'' && yamlBooleans[strings.ToLower(value)] {
+			continue
+		}
+		pairs = append(pairs, keyValue{
+			Key: key, Value: value, Line: i + 1,
+			KeyColumn: m[4] + 1, ValueColumn: from + 1, ValueEnd: to + 1,
+		})
+	}
+	return pairs
+}
+
+// unquoteYAML returns the text of a double- or single-quoted YAML scalar.
+func unquoteYAML(s string) string {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s[1 : len(s)-1]
+}
+
+// scalarSpan returns the byte range of the value starting at start in line:
+// the text between its quotes, or an unquoted value up to a " #" comment and
+// without trailing whitespace.
+func scalarSpan(line string, start int) (from, to int) {
+	if start >= len(line) {
+		return start, start
+	}
+	switch q := line[start]; q {
+	case '"', '\'':
+		for i := start + 1; i < len(line); i++ {
+			switch {
+			case q == '"' && line[i] == '\\':
+				i++
+			case line[i] != q:
+			case q == '\'' && i+1 < len(line) && line[i+1] == '\'':
+				i++
+			default:
+				return start + 1, i
+			}
+		}
+		return start + 1, len(line)
+	}
+	end := len(line)
+	if c := strings.Index(line[start:], " #"); c >= 0 {
+		end = start + c
+	}
+	return start, start + len(strings.TrimRight(line[start:end], " \t"))
+}
+
+// keyWords splits key into lowercase words at punctuation and camelCase
+// boundaries: "dbPassword" and "DB_PASSWORD" both give db, password.
+func keyWords(key string) []string {
+	var (
+		words []string
+		word  []rune
+		prev  rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, r := range key {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+		prev = r
+	}
+	flush()
+	return words
+}
+
+// isSecretKey reports whether key names a secret: its last word is a
+// secretKeyWords entry, or "key" qualified by a secretKeyQualifiers entry.
+func isSecretKey(key string) bool {
+	words := keyWords(key)
+	n := len(words)
+	switch {
+	case n == 0:
+		return false
+	case secretKeyWords[words[n-1]]:
+		return true
+	default:
+		return n >= 2 && words[n-1] == "key" && secretKeyQualifiers[words[n-2]]
+	}
+}
+
+// readStructured reads the file path from r and, if it is a structured file
+// of at most maxStructuredSize bytes, records its key/value pairs by line. It
+// returns a reader of the whole file.
+func (ls *lineScanner) readStructured(r io.Reader) (io.Reader, error) {
+	format := structuredFormat(ls.path)
+	if format == "" {
+		return r, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxStructuredSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) <= maxStructuredSize {
+		ls.pairs = make(map[int][]keyValue)
+		for _, kv := range parseStructured(format, data) {
+			ls.pairs[kv.Line] = append(ls.pairs[kv.Line], kv)
+		}
+	}
+	return io.MultiReader(bytes.NewReader(data), r), nil
+}
+
+// applyStructure reconciles the matches on chunk with the key/value pairs of
+// its line. Matches on a secret-like key whose value is a placeholder are
+// dropped and recorded, and a secret-like key with a literal value that no
+// match covers is reported by structuredSecretRule.
+func (ls *lineScanner) applyStructure(chunk lineChunk, matches []Match) []Match {
+	for _, kv := range ls.pairs[chunk.LineNo] {
+		// A pair belongs to the window holding the whole of its value.
+		if kv.ValueColumn-1 < chunk.Offset+chunk.Skip || kv.ValueEnd-1 > chunk.Offset+len(chunk.Text) || !isSecretKey(kv.Key) {
+			continue
+		}
+		if reason := placeholderReason(kv.Value); reason != "" {
+			matches = slices.DeleteFunc(matches, func(m Match) bool {
+				onPair := m.Column < max(kv.ValueEnd, kv.ValueColumn+1) && kv.KeyColumn < m.EndColumn
+				if onPair {
+					ls.notePlaceholder(m, reason)
+				}
+				return onPair
+			})
+			continue
+		}
+		covered := slices.ContainsFunc(matches, func(m Match) bool {
+			return m.Column < kv.ValueEnd && kv.ValueColumn < m.EndColumn
+		})
+		if covered || len(kv.Value) < minStructuredValueLength {
+			continue
+		}
+		if m, ok := structuredMatch(ls.opts, ls.path, chunk, kv); ok {
+			matches = append(matches, m)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Column < matches[j].Column
+	})
+	return matches
+}
+
+// structuredMatch returns the finding for the literal value of kv on chunk,
+// unless the exclude patterns filter out the pair's own text.
+func structuredMatch(opts scanOptions, path string, chunk lineChunk, kv keyValue) (Match, bool) {
+	line := chunk.Text
+	loc := []int{kv.ValueColumn - 1 - chunk.Offset, kv.ValueEnd - 1 - chunk.Offset}
+	// The pair runs from its key to the value's closing quote, if any.
+	pair := line[max(0, kv.KeyColumn-1-chunk.Offset):min(len(line), loc[1]+1)]
+	if opts.ExcludePatterns.MatchString(pair) {
+		return Match{}, false
+	}
+	snippet := strings.TrimSpace(line)
+	if chunk.Windowed {
+		snippet = snippetAround(line, loc)
+	}
+	return Match{
+		File:        path,
+		Line:        chunk.LineNo,
+		LineSnippet: snippet,
+		MatchText:   line[loc[0]:loc[1]],
+		Column:      kv.ValueColumn,
+		EndColumn:   kv.ValueEnd,
+		RuleID:      structuredSecretRule.ID,
+		Severity:    structuredSecretRule.Severity,
+		Entropy:     roundEntropy(shannonEntropy(kv.Value)),
+	}, true
+}
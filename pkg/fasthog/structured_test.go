@@ -0,0 +1,143 @@
+package fasthog
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStructuredFormat(t *testing.T) {
+	tests := map[string]string{
+		".env":                     formatEnv,
+		"deploy/.env.production":   formatEnv,
+		"local.env":                formatEnv,
+		"conf/app.properties":      formatProperties,
+		"package.json":             formatJSON,
+		".github/workflows/ci.yml": formatYAML,
+		"chart/values.YAML":        formatYAML,
+		"app.jar!/config.json":     formatJSON,
+		"main.go":                  "",
+		"environment.py":           "",
+	}
+	for p, want := range tests {
+		if got := structuredFormat(p); got != want {
+			t.Errorf("structuredFormat(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
+
+func TestParseStructured(t *testing.T) {
+	tests := []struct {
+		format string
+		data   string
+		want   []keyValue
+	}{
+		{formatEnv, "# comment\nexport API_TOKEN=abc123 # note\nDB_PASSWORD='it''s'\nEMPTY=\n", []keyValue{
+			{Key: "API_TOKEN", Value: "abc123", Line: 2, KeyColumn: 8, ValueColumn: 18, ValueEnd: 24},
+			{Key: "DB_PASSWORD", Value: "it''s", Line: 3, KeyColumn: 1, ValueColumn: 14, ValueEnd: 19},
+			{Key: "EMPTY", Value: "", Line: 4, KeyColumn: 1, ValueColumn: 7, ValueEnd: 7},
+		}},
+		{formatProperties, "! comment\ndb.password = s3cret\nlong=first \\\n  second=line\nkey:value\n", []keyValue{
+			{Key: "db.password", Value: "s3cret", Line: 2, KeyColumn: 1, ValueColumn: 15, ValueEnd: 21},
+			{Key: "long", Value: "first ", Line: 3, KeyColumn: 1, ValueColumn: 6, ValueEnd: 12},
+			{Key: "key", Value: "value", Line: 5, KeyColumn: 1, ValueColumn: 5, ValueEnd: 10},
+		}},
+		{formatJSON, "{\n  \"db\": {\"password\": \"p\\\"w\", \"port\": 5432},\n  \"hosts\": [\"a\"],\n  \"token\":\n    \"split\"\n}\n", []keyValue{
+			{Key: "password", Value: `p"w`, Line: 2, KeyColumn: 10, ValueColumn: 23, ValueEnd: 27},
+		}},
+		{formatYAML, "---\nservices:\n  - name: web # comment\n    apiKey: &k \"abc\"\n    copy: *k\n    script: |\n      token: inside\n    enabled: true\n    'quoted key': 'v'\n", []keyValue{
+			{Key: "name", Value: "web", Line: 3, KeyColumn: 5, ValueColumn: 11, ValueEnd: 14},
+			{Key: "apiKey", Value: "abc", Line: 4, KeyColumn: 5, ValueColumn: 17, ValueEnd: 20},
+			{Key: "quoted key", Value: "v", Line: 9, KeyColumn: 5, ValueColumn: 20, ValueEnd: 21},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got := parseStructured(tt.format, []byte(tt.data))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseStructured() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSecretKey(t *testing.T) {
+	tests := map[string]bool{
+		"ADMIN_PASSWORD":    true,
+		"dbPassword":        true,
+		"db.pass":           true,
+		"x-api-key":         true,
+		"AWS_SECRET":        true,
+		"private_key":       true,
+		"authToken":         true,
+		"key":               false,
+		"bypass":            false,
+		"token_url":         false,
+		"password_file":     false,
+		"secretKeyRef":      false,
+		"primary_key":       false,
+		"passwordMinLength": false,
+	}
+	for key, want := range tests {
+		if got := isSecretKey(key); got != want {
+			t.Errorf("isSecretKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// TestScanReaderStructured verifies that placeholder values of secret-like
+// keys are dropped without exclude patterns, and that literal values the
+// patterns miss are reported.
+func TestScanReaderStructured(t *testing.T) {
+	ps := testPatterns(t)
+	noExclude := regexp.MustCompile(`[^\x00-\x{10FFFF}]`)
+	opts := scanOptions{ExcludePatterns: noExclude, FastPatterns: ps.Fast, SlowPatterns: ps.Slow}
+
+	// [FP06], [FP07], [FP08] and [FP10] from test/False_Positives.txt.
+	placeholders := strings.Join([]string{
+		`ADMIN_PASSWORD: "${ADMIN_PASSWORD}"`,
+		`POSTGRES_PASSWORD: "${POSTGRES_PASSWORD}"`,
+		`REPO_PASSWORD: ${{ secrets.REPO_PASSWORD }}`,
+		`password: ""`,
+	}, "\n")
+	res := scanReader(opts, "ci.yml", strings.NewReader(placeholders))
+	if len(res.Matches) != 0 {
+		t.Errorf("expected no findings for placeholder values, got %+v", res.Matches)
+	}
+	// password: "" matches no pattern in the first place.
+	if len(res.Placeholders) != 3 {
+		t.Errorf("expected 3 placeholder matches to be recorded, got %+v", res.Placeholders)
+	}
+
+	tests := []struct {
+		path    string
+		content string
+		rule    string
+		text    string
+	}{
+		{"config.json", `{"db": {"dbPassword": "s3cr3tValue", "apiKey": "${API_KEY}"}}`, structuredSecretRule.ID, "s3cr3tValue"},
+		{"values.yaml", "signing:\n  passphrase: 'Zq8xLm2Vp4'\n", structuredSecretRule.ID, "Zq8xLm2Vp4"},
+		{".env", "DB_PASSWORD=\"mysecretpassword123\"\n", "quoted-secret-assignment", `PASSWORD="mysecretpassword123"`},
+		{"app.properties", "db.password=${DB_PASSWORD}\nui.theme=dark\n", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			res := scanReader(opts, tt.path, strings.NewReader(tt.content))
+			if tt.rule == "" {
+				if len(res.Matches) != 0 {
+					t.Errorf("expected no findings, got %+v", res.Matches)
+				}
+				return
+			}
+			if len(res.Matches) != 1 || res.Matches[0].RuleID != tt.rule || res.Matches[0].MatchText != tt.text {
+				t.Fatalf("expected one %s finding of %q, got %+v", tt.rule, tt.text, res.Matches)
+			}
+			m := res.Matches[0]
+			line := strings.Split(tt.content, "\n")[m.Line-1]
+			if got := line[m.Column-1 : m.EndColumn-1]; got != tt.text {
+				t.Errorf("columns locate %q, want %q", got, tt.text)
+			}
+		})
+	}
+}
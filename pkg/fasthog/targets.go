@@ -0,0 +1,95 @@
+package fasthog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// StdinTarget is the scan target, and --files-from value, naming standard
+// input.
+const StdinTarget = "-"
+
+// StdinPath is the pseudo-path findings read from standard input are
+// attributed to.
+const StdinPath = "<stdin>"
+
+// ValidateTargets checks the paths named on the command line before any
+// output is produced. At most one of them may read standard input.
+func ValidateTargets(targets []string) error {
+	stdin := 0
+	for _, t := range targets {
+		if t == StdinTarget {
+			if stdin++; stdin > 1 {
+				return fmt.Errorf("standard input (%s) can only be scanned once", StdinTarget)
+			}
+			continue
+		}
+		if _, err := os.Stat(t); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("path does not exist: %s", t)
+			}
+			return fmt.Errorf("cannot access path: %w", err)
+		}
+	}
+	return nil
+}
+
+// scanTargets scans a mix of files, directories and standard input. Files
+// are scanned whatever their extension, as they were named explicitly, and
+// attributed to the path given; directories are walked as by scanDirectory
+// and their findings attributed to paths joined with the directory.
+func scanTargets(opts scanOptions, targets []string) Result {
+	result := Result{MatchFiles: make(map[string]int)}
+
+	var files []string
+	for _, t := range targets {
+		if t == StdinTarget {
+			files = append(files, StdinPath)
+			continue
+		}
+		info, err := os.Stat(t)
+		if err != nil {
+			result.Errors = append(result.Errors, ScanError{File: filepath.ToSlash(t), Op: "open", Err: err})
+			continue
+		}
+		if !info.IsDir() {
+			files = append(files, filepath.ToSlash(t))
+			continue
+		}
+
+		dirOpts := opts
+		dirOpts.Directory, dirOpts.JoinDirectory = t, true
+		mergeScanResult(&result, scanDirectory(dirOpts))
+	}
+
+	open := func(p string) (io.ReadCloser, error) {
+		if p == StdinPath {
+			return io.NopCloser(os.Stdin), nil
+		}
+		return os.Open(filepath.FromSlash(p))
+	}
+	result.Filenames = append(result.Filenames, files...)
+	scanFiles(opts, &result, files, open)
+	scanSensitiveFiles(opts, &result, slices.DeleteFunc(slices.Clone(files), func(p string) bool {
+		return p == StdinPath
+	}), open)
+	return result
+}
+
+// mergeScanResult adds the results of a scanDirectory call to result.
+func mergeScanResult(result *Result, dirRes Result) {
+	result.Matches = append(result.Matches, dirRes.Matches...)
+	for file, count := range dirRes.MatchFiles {
+		result.MatchFiles[file] += count
+	}
+	result.Filenames = append(result.Filenames, dirRes.Filenames...)
+	result.Errors = append(result.Errors, dirRes.Errors...)
+	result.Warnings = append(result.Warnings, dirRes.Warnings...)
+	result.Suppressed += dirRes.Suppressed
+	result.Ignored += dirRes.Ignored
+	result.Skipped += dirRes.Skipped
+	result.Placeholders = append(result.Placeholders, dirRes.Placeholders...)
+}
@@ -0,0 +1,98 @@
+package fasthog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScanTargets(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"repo/app/config.py": "PASSWORD=\"mysecretpassword123\"\n",
+		"repo/notes.bin":     "PASSWORD=\"skippedbyextension1\"\n",
+		"single.conf":        "x = 1\nTOKEN=\"anothersecretvalue9\"\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Replace stdin with a pipe carrying a third secret.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString("api_key = \"sk_live_abcdef1234567890\"\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	oldStdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	opts := testScanOptions(t, scanOptions{
+		Extensions: []string{".py"},
+	})
+
+	repo := filepath.Join(dir, "repo")
+	single := filepath.Join(dir, "single.conf")
+	missing := filepath.Join(dir, "missing.py")
+	res := scanTargets(opts, []string{repo, single, StdinTarget, missing})
+
+	var got []string
+	for _, m := range res.Matches {
+		got = append(got, fmt.Sprintf("%s:%d", m.File, m.Line))
+	}
+	slices.Sort(got)
+	want := []string{
+		filepath.ToSlash(filepath.Join(repo, "app", "config.py")) + ":1",
+		StdinPath + ":1",
+		filepath.ToSlash(single) + ":2",
+	}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("expected findings %v, got %v", want, got)
+	}
+	if len(res.Filenames) != 3 {
+		t.Errorf("expected 3 scanned files (the .bin file is filtered), got %v", res.Filenames)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].File != filepath.ToSlash(missing) {
+		t.Errorf("expected an error for the missing file, got %+v", res.Errors)
+	}
+}
+
+func TestValidateTargets(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		targets []string
+		wantErr string
+	}{
+		{"directory and stdin", []string{dir, StdinTarget}, ""},
+		{"missing path", []string{filepath.Join(dir, "nope.py")}, "path does not exist"},
+		{"stdin twice", []string{StdinTarget, StdinTarget}, "only be scanned once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTargets(tt.targets)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
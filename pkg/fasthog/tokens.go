@@ -0,0 +1,61 @@
+package fasthog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"hash/crc32"
+	"strings"
+)
+
+// base62Alphabet is the digit order of the base62 checksums in GitHub and
+// npm tokens.
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// crc32TokenEntropy and crc32TokenChecksum are the lengths of the random part
+// and the checksum of a token after its prefix, as in ghp_<30><6>.
+const (
+	crc32TokenEntropy  = 30
+	crc32TokenChecksum = 6
+)
+
+// checksumValidators are the checksums a rule can require with its
+// "checksum=" annotation, by name. Each reports whether a matched token is
+// well-formed; none of them contacts the provider.
+var checksumValidators = map[string]func(token string) bool{
+	"crc32-base62":  validCRC32Token,
+	"pypi-macaroon": validPyPIToken,
+}
+
+// validCRC32Token reports whether the part of token after its last
+// underscore is 30 random characters followed by the 6-digit base62 CRC32 of
+// them, the format of GitHub ghp_/gho_/ghu_/ghs_/ghr_ and npm npm_ tokens.
+func validCRC32Token(token string) bool {
+	body := token[strings.LastIndexByte(token, '_')+1:]
+	if len(body) != crc32TokenEntropy+crc32TokenChecksum {
+		return false
+	}
+	random := body[:crc32TokenEntropy]
+	return body[crc32TokenEntropy:] == crc32Base62(random)
+}
+
+// crc32Base62 returns the CRC32 of s in base62, zero-padded to six digits.
+func crc32Base62(s string) string {
+	var digits [crc32TokenChecksum]byte
+	n := crc32.ChecksumIEEE([]byte(s))
+	for i := len(digits) - 1; i >= 0; i-- {
+		digits[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+	return string(digits[:])
+}
+
+// validPyPIToken reports whether token is "pypi-" followed by a base64url
+// macaroon issued by pypi.org.
+func validPyPIToken(token string) bool {
+	body, ok := strings.CutPrefix(token, "pypi-")
+	if !ok {
+		return false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(body, "="))
+	return err == nil && bytes.Contains(data, []byte("pypi.org"))
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/bordenet/secrets-in-source/pkg/fasthog"
+)
+
+// SARIF 2.1.0 output, as consumed by code-scanning dashboards and IDE SARIF
+// viewers. Only the subset of the schema fasthog populates is modelled; see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// sarifSrcRoot is the conventional base ID for paths relative to the
+	// scanned directory, resolved by the run's originalUriBaseIds.
+	sarifSrcRoot = "%SRCROOT%"
+
+	// sarifFingerprintKey names fasthog's fingerprint in partialFingerprints.
+	sarifFingerprintKey = "fasthogFingerprint/v1"
+
+	fasthogInformationURI = "https://github.com/bordenet/secrets-in-source"
+)
+
+// SARIFLog is the top-level SARIF document.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun describes one invocation of fasthog and its results.
+type SARIFRun struct {
+	Tool               SARIFTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]SARIFArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Invocations        []SARIFInvocation                `json:"invocations"`
+	Results            []SARIFResult                    `json:"results"`
+}
+
+// SARIFTool identifies fasthog and its rules.
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver lists the rules that results refer to by index.
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is a reportingDescriptor for one detection rule.
+type SARIFRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *SARIFMessage       `json:"shortDescription,omitempty"`
+	DefaultConfiguration SARIFConfiguration  `json:"defaultConfiguration"`
+	Properties           SARIFRuleProperties `json:"properties"`
+}
+
+// SARIFConfiguration holds a rule's default level.
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+// SARIFRuleProperties carries the tags and numeric severity used by GitHub
+// code scanning to rank security findings.
+type SARIFRuleProperties struct {
+	Tags             []string `json:"tags"`
+	SecretType       string   `json:"secret_type"`
+	SecuritySeverity string   `json:"security-severity"`
+	// Files is the scope of a rule in a pattern file section.
+	Files string `json:"files,omitempty"`
+}
+
+// SARIFMessage is a plain-text message.
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFInvocation reports whether the run completed and any files that
+// could not be scanned completely.
+type SARIFInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []SARIFNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+// SARIFNotification is a scan error or warning.
+type SARIFNotification struct {
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFResult is a single finding.
+type SARIFResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+// SARIFLocation wraps a physical location.
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a file and, for findings, a region within it.
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation is a URI, relative to URIBaseID when set, or a
+// description of an artifact that has none, such as standard input.
+type SARIFArtifactLocation struct {
+	URI         string        `json:"uri,omitempty"`
+	URIBaseID   string        `json:"uriBaseId,omitempty"`
+	Description *SARIFMessage `json:"description,omitempty"`
+}
+
+// SARIFRegion uses 1-based lines and columns; EndColumn is exclusive, as for
+// Match.
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifLevel maps a severity to a SARIF result level.
+func sarifLevel(s fasthog.Severity) string {
+	switch s {
+	case fasthog.SeverityCritical, fasthog.SeverityHigh:
+		return "error"
+	case fasthog.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps a severity to the CVSS-like score GitHub code
+// scanning uses to label security findings.
+func sarifSecuritySeverity(s fasthog.Severity) string {
+	switch s {
+	case fasthog.SeverityCritical:
+		return "9.5"
+	case fasthog.SeverityHigh:
+		return "8.0"
+	case fasthog.SeverityMedium:
+		return "5.5"
+	default:
+		return "3.0"
+	}
+}
+
+// sarifFileURI returns the file URI of the absolute path, with the drive
+// letter of a Windows path as its first segment.
+func sarifFileURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
+
+// sarifFileLocation returns the location of path: a percent-encoded URI
+// relative to the source root, or a file URI if path is absolute. Standard
+// input has no URI and is only described.
+func sarifFileLocation(path string) SARIFArtifactLocation {
+	if path == fasthog.StdinPath {
+		return SARIFArtifactLocation{Description: &SARIFMessage{Text: "standard input"}}
+	}
+	if filepath.IsAbs(path) {
+		return SARIFArtifactLocation{URI: sarifFileURI(path)}
+	}
+	return SARIFArtifactLocation{URI: (&url.URL{Path: filepath.ToSlash(path)}).String(), URIBaseID: sarifSrcRoot}
+}
+
+// buildSARIF converts a scan result into a SARIF log describing rules.
+// Relative paths resolve against srcRoot, the directory they are relative
+// to, if it is known. Messages name the rule rather than quoting the secret.
+func buildSARIF(rules []fasthog.Rule, res fasthog.Result, srcRoot string) SARIFLog {
+	driver := SARIFDriver{
+		Name:           "fasthog",
+		InformationURI: fasthogInformationURI,
+		Rules:          make([]SARIFRule, 0, len(rules)),
+	}
+	ruleIndex := make(map[string]int, len(rules))
+	for _, rule := range rules {
+		ruleIndex[rule.ID] = len(driver.Rules)
+		sr := SARIFRule{
+			ID:                   rule.ID,
+			DefaultConfiguration: SARIFConfiguration{Level: sarifLevel(rule.Severity)},
+			Properties: SARIFRuleProperties{
+				Tags:             []string{"security", "secret"},
+				SecretType:       rule.SecretType,
+				SecuritySeverity: sarifSecuritySeverity(rule.Severity),
+				Files:            rule.Files,
+			},
+		}
+		if rule.Description != "" {
+			sr.ShortDescription = &SARIFMessage{Text: rule.Description}
+		}
+		driver.Rules = append(driver.Rules, sr)
+	}
+
+	results := make([]SARIFResult, 0, len(res.Matches))
+	for _, m := range res.Matches {
+		// Results with an unknown rule keep SARIF's "no index" value of -1.
+		idx, ok := ruleIndex[m.RuleID]
+		if !ok {
+			idx = -1
+		}
+		text := "Potential secret matched rule " + m.RuleID
+		if ok && rules[idx].Description != "" {
+			text = rules[idx].Description + " (" + m.RuleID + ")"
+		}
+		// Files reported by name have no region; the whole file is the finding.
+		var region *SARIFRegion
+		if !m.IsFileFinding() {
+			region = &SARIFRegion{StartLine: m.Line, StartColumn: m.Column, EndColumn: m.EndColumn}
+			if m.EndLine > m.Line {
+				region.EndLine = m.EndLine
+			}
+		}
+
+		result := SARIFResult{
+			RuleID:    m.RuleID,
+			RuleIndex: idx,
+			Level:     sarifLevel(m.Severity),
+			Message:   SARIFMessage{Text: text},
+			Locations: []SARIFLocation{{PhysicalLocation: SARIFPhysicalLocation{
+				ArtifactLocation: sarifFileLocation(m.File),
+				Region:           region,
+			}}},
+			PartialFingerprints: map[string]string{sarifFingerprintKey: m.Fingerprint},
+		}
+		if m.Commit != nil {
+			result.Properties = map[string]any{"commit": m.Commit}
+		}
+		if m.Encoded != "" {
+			if result.Properties == nil {
+				result.Properties = map[string]any{}
+			}
+			result.Properties["encoded"] = m.Encoded
+		}
+		results = append(results, result)
+	}
+
+	invocation := SARIFInvocation{ExecutionSuccessful: true}
+	for _, e := range res.Errors {
+		n := SARIFNotification{Level: "error", Message: SARIFMessage{Text: e.Error()}}
+		if e.File != "" {
+			n.Locations = []SARIFLocation{{PhysicalLocation: SARIFPhysicalLocation{ArtifactLocation: sarifFileLocation(e.File)}}}
+		}
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, n)
+	}
+	for _, w := range res.Warnings {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, SARIFNotification{
+			Level:   "warning",
+			Message: SARIFMessage{Text: w.String()},
+		})
+	}
+
+	run := SARIFRun{
+		Tool:        SARIFTool{Driver: driver},
+		Invocations: []SARIFInvocation{invocation},
+		Results:     results,
+	}
+	if srcRoot != "" {
+		// A base URI must end with a slash for relative URIs to resolve
+		// within it.
+		root := sarifFileURI(srcRoot)
+		if !strings.HasSuffix(root, "/") {
+			root += "/"
+		}
+		run.OriginalURIBaseIDs = map[string]SARIFArtifactLocation{sarifSrcRoot: {URI: root}}
+	}
+
+	return SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []SARIFRun{run},
+	}
+}
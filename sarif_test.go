@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bordenet/secrets-in-source/pkg/fasthog"
+)
+
+func TestBuildSARIF(t *testing.T) {
+	rules := []fasthog.Rule{
+		{ID: "api-key-quoted", Description: "API key assigned a double-quoted value", SecretType: "api-key", Severity: fasthog.SeverityHigh},
+		{ID: "private-key-block", Description: "Private key block", SecretType: "private-key", Severity: fasthog.SeverityCritical},
+	}
+	res := fasthog.Result{
+		Matches: []fasthog.Match{
+			{File: "app/config.py", Line: 3, Column: 5, EndColumn: 30, RuleID: "api-key-quoted", Severity: fasthog.SeverityHigh, MatchText: `API_KEY="abc"`, Fingerprint: "f1"},
+			{File: "keys/id_rsa.key", Line: 1, EndLine: 27, Column: 1, EndColumn: 30, RuleID: "private-key-block", Severity: fasthog.SeverityCritical, Fingerprint: "f2"},
+		},
+		Errors:   []fasthog.ScanError{{File: "locked.py", Op: "open", Err: errors.New("permission denied")}},
+		Warnings: []fasthog.ScanWarning{{File: "bundle.js", Line: 1, Message: "long line"}},
+	}
+
+	data, err := json.Marshal(buildSARIF(rules, res, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `API_KEY=\"abc\"`) {
+		t.Error("SARIF output should not quote the secret")
+	}
+
+	// Decode generically to check the document against the SARIF property names.
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Invocations []struct {
+				Notifications []json.RawMessage `json:"toolExecutionNotifications"`
+			} `json:"invocations"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+							EndLine     int `json:"endLine"`
+							EndColumn   int `json:"endColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("expected one SARIF 2.1.0 run, got version %q with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if wantRules := len(rules); run.Tool.Driver.Name != "fasthog" || len(run.Tool.Driver.Rules) != wantRules {
+		t.Errorf("expected fasthog driver with %d rules, got %+v", wantRules, run.Tool.Driver)
+	}
+	if len(run.Invocations) != 1 || len(run.Invocations[0].Notifications) != 2 {
+		t.Errorf("expected the error and warning as notifications, got %+v", run.Invocations)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(run.Results))
+	}
+
+	first := run.Results[0]
+	region := first.Locations[0].PhysicalLocation.Region
+	if first.RuleID != "api-key-quoted" || first.RuleIndex != 0 || first.Level != "error" {
+		t.Errorf("unexpected rule reference %+v", first)
+	}
+	if first.Locations[0].PhysicalLocation.ArtifactLocation.URI != "app/config.py" ||
+		region.StartLine != 3 || region.StartColumn != 5 || region.EndColumn != 30 || region.EndLine != 0 {
+		t.Errorf("unexpected location %+v", first.Locations[0])
+	}
+	if first.PartialFingerprints[sarifFingerprintKey] != "f1" {
+		t.Errorf("unexpected partial fingerprints %v", first.PartialFingerprints)
+	}
+
+	block := run.Results[1]
+	if block.RuleIndex != 1 || block.Locations[0].PhysicalLocation.Region.EndLine != 27 {
+		t.Errorf("unexpected multi-line result %+v", block)
+	}
+}
+
+func TestSARIFFileLocations(t *testing.T) {
+	abs, err := filepath.Abs(filepath.Join("scan root", "id_rsa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := fasthog.Result{Matches: []fasthog.Match{
+		{File: "src/my app/config#1.py", Line: 1, RuleID: "generic-secret"},
+		{File: abs, RuleID: "ssh-private-key-file"},
+	}}
+	log := buildSARIF(nil, res, filepath.Dir(abs))
+
+	rel := log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation
+	if rel.URI != "src/my%20app/config%231.py" || rel.URIBaseID != sarifSrcRoot {
+		t.Errorf("relative path: got %+v, want a percent-encoded URI under %s", rel, sarifSrcRoot)
+	}
+
+	loc := log.Runs[0].Results[1].Locations[0].PhysicalLocation.ArtifactLocation
+	u, err := url.Parse(loc.URI)
+	if err != nil || u.Scheme != "file" || loc.URIBaseID != "" || !strings.HasSuffix(loc.URI, "/scan%20root/id_rsa") {
+		t.Errorf("absolute path: got %+v, want a file URI without a base", loc)
+	}
+
+	root, ok := log.Runs[0].OriginalURIBaseIDs[sarifSrcRoot]
+	if !ok || !strings.HasPrefix(root.URI, "file:///") || !strings.HasSuffix(root.URI, "/scan%20root/") {
+		t.Errorf("expected %s to resolve to the scan root, got %+v", sarifSrcRoot, log.Runs[0].OriginalURIBaseIDs)
+	}
+}
+
+func TestSARIFStdinLocation(t *testing.T) {
+	res := fasthog.Result{Matches: []fasthog.Match{{File: fasthog.StdinPath, Line: 2, Column: 1, EndColumn: 9, RuleID: "generic-secret"}}}
+	loc := buildSARIF(nil, res, "").Runs[0].Results[0].Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "" || loc.ArtifactLocation.URIBaseID != "" || loc.ArtifactLocation.Description == nil {
+		t.Errorf("expected standard input to be described without a URI, got %+v", loc.ArtifactLocation)
+	}
+	if loc.Region == nil || loc.Region.StartLine != 2 {
+		t.Errorf("expected the region to be kept, got %+v", loc.Region)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bordenet/secrets-in-source/pkg/fasthog"
+)
+
+// readFileList reads the newline-separated paths listed in the --files-from
+// file, or on standard input for "-". Blank lines are skipped.
+func readFileList(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != fasthog.StdinTarget {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file list: %w", err)
+		}
+		defer func() {
+			_ = f.Close() // Read-only; nothing to flush
+		}()
+		r = f
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if p := strings.TrimSuffix(scanner.Text(), "\r"); strings.TrimSpace(p) != "" {
+			paths = append(paths, p)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read file list %s: %w", name, err)
+	}
+	return paths, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadFileList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "files.txt")
+	if err := os.WriteFile(path, []byte("a.py\r\n\nsub dir/b.env\n  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readFileList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.py", "sub dir/b.env"}; !slices.Equal(got, want) {
+		t.Errorf("readFileList() = %q, want %q", got, want)
+	}
+
+	if _, err := readFileList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error for a missing file list")
+	}
+}